@@ -84,14 +84,17 @@ func main() {
 	// Build the wallet card
 	walletCard := eventTicket.Build()
 
-	// Create Add to Wallet link using new method
-	link, err := client.CreateATWLinkFromWalletCard(cardID, walletCard, "data_transmit")
+	// Create Add to Wallet link
+	link, err := client.CreateLink(cardID, walletCard,
+		wallet.WithLinkType(wallet.LinkTypeDataTransmit),
+		wallet.WithCallbackURL("https://developer.samsung.com/wallet"),
+	)
 	if err != nil {
 		log.Fatalf("Failed to create event ticket link: %v", err)
 	}
 
-	fmt.Printf("Event Ticket ATW Link: %s\n", link)
-	fmt.Printf("Note: CDATA expires in 30 seconds for security\n")
+	fmt.Printf("Event Ticket ATW Link: %s\n", link.URL)
+	fmt.Printf("Note: CDATA expires at %s (30 seconds) for security\n", link.ExpiresAt.Format(time.RFC3339))
 
 	// Display card details
 	fmt.Printf("\nCard Details:\n")
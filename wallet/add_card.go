@@ -0,0 +1,97 @@
+package wallet
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+)
+
+// Samsung server-to-server add card API
+
+// AddCardRequest pushes a card straight into a user's Samsung Wallet without an ATW link
+//
+// Exactly one of UserID or DeviceID identifies where the card is added; both come
+// from the partner's Samsung account linking flow.
+type AddCardRequest struct {
+	CardID      string     // Card ID from Partners Portal (identifies the card type registration)
+	CountryCode string     // cc2 country code of the user
+	UserID      string     // Samsung account user identifier
+	DeviceID    string     // Device identifier, used when the card targets a single device
+	Card        WalletCard // Card to add, sent as CDATA
+}
+
+// AddCardResult represents Samsung's response to an add card request
+type AddCardResult struct {
+	NotificationResult
+	RefID string    `json:"refId"` // refId of the created card
+	State CardState `json:"state"` // State of the created card, e.g. CardStateAdded
+}
+
+// addCardBody is the wire format of an add card request
+type addCardBody struct {
+	Card   addCardPayload `json:"card"`
+	Target addCardTarget  `json:"target"`
+}
+
+// addCardPayload carries the encrypted card data
+type addCardPayload struct {
+	CDATA string `json:"cdata"`
+}
+
+// addCardTarget identifies the user or device the card is added to
+type addCardTarget struct {
+	UserID   string `json:"userId,omitempty"`
+	DeviceID string `json:"deviceId,omitempty"`
+}
+
+// AddCard adds a card to a user's Samsung Wallet from the partner server
+func (c *Client) AddCard(ctx context.Context, request *AddCardRequest) (*AddCardResult, error) {
+	if request == nil {
+		return nil, fmt.Errorf("add card request cannot be nil")
+	}
+	if request.CardID == "" {
+		return nil, fmt.Errorf("card ID is required (obtain from Partners Portal when registering card type)")
+	}
+	if request.CountryCode == "" {
+		return nil, fmt.Errorf("country code is required")
+	}
+	if (request.UserID == "") == (request.DeviceID == "") {
+		return nil, fmt.Errorf("exactly one of user ID or device ID is required")
+	}
+	if len(request.Card.Card.Data) == 0 {
+		return nil, fmt.Errorf("card data is required")
+	}
+
+	cdata, err := c.jwtManager.CreateCDATAWithContext(ctx, request.Card)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create CDATA token: %v", err)
+	}
+
+	response, err := c.makeAPIRequest(ctx, apiRequest{
+		method:      "POST",
+		path:        fmt.Sprintf(pathAddCard, url.PathEscape(request.CountryCode), url.PathEscape(request.CardID)),
+		countryCode: request.CountryCode,
+		refID:       request.Card.Card.Data[0].RefID,
+		payload: addCardBody{
+			Card: addCardPayload{CDATA: cdata},
+			Target: addCardTarget{
+				UserID:   request.UserID,
+				DeviceID: request.DeviceID,
+			},
+		},
+		// Not idempotent: a repeated add may create a duplicate card, so only
+		// failures Samsung reports as unprocessed are retried
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &AddCardResult{}
+	if len(response) > 0 {
+		if err := json.Unmarshal(response, result); err != nil {
+			return nil, fmt.Errorf("failed to parse add card result: %v", err)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,280 @@
+package wallet
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	_ "image/gif"  // Register GIF decoder for DecodeConfig
+	_ "image/jpeg" // Register JPEG decoder for DecodeConfig
+	_ "image/png"  // Register PNG decoder for DecodeConfig
+	"io"
+	"math"
+	"mime"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// defaultAssetTimeout bounds each image fetch when no http.Client is given
+	defaultAssetTimeout = 10 * time.Second
+
+	// maxAssetDownload caps downloads when some rule has no size limit
+	maxAssetDownload = 20 << 20
+)
+
+// AssetRule describes the constraints of one image attribute
+type AssetRule struct {
+	MaxBytes        int64   // Maximum file size in bytes; 0 means up to 20 MB, the most that is downloaded
+	AspectRatio     float64 // Required width/height ratio; 0 means any
+	AspectTolerance float64 // Allowed relative deviation from AspectRatio (default 0.05)
+	MinWidth        int     // Minimum width in pixels; 0 means any
+	MinHeight       int     // Minimum height in pixels; 0 means any
+}
+
+// DefaultAssetRules returns the Samsung Wallet constraints of the ticket image attributes
+func DefaultAssetRules() map[string]AssetRule {
+	logo := AssetRule{MaxBytes: 256 << 10, AspectRatio: 1}
+	other := AssetRule{MaxBytes: 256 << 10}
+	return map[string]AssetRule{
+		"mainImg":                 {MaxBytes: 512 << 10},
+		"logoImage":               logo,
+		"logoImage.darkUrl":       logo,
+		"logoImage.lightUrl":      logo,
+		"appLinkLogo":             logo,
+		"wideImage":               other,
+		"seatLayoutImage":         other,
+		"relCoupon1.imageFileSrc": other,
+		"relCoupon2.imageFileSrc": other,
+		"relCoupon3.imageFileSrc": other,
+	}
+}
+
+// allowedImageTypes are the image content types Samsung Wallet renders
+var allowedImageTypes = map[string]string{
+	"image/png":  "png",
+	"image/jpeg": "jpeg",
+	"image/gif":  "gif",
+}
+
+// AssetChecker fetches the images referenced by a card and checks them against AssetRules
+type AssetChecker struct {
+	httpClient *http.Client
+	allowHTTP  bool
+	rules      map[string]AssetRule
+}
+
+// AssetOption configures an AssetChecker
+type AssetOption func(*AssetChecker)
+
+// WithAssetHTTPClient sets the client used to fetch images
+func WithAssetHTTPClient(client *http.Client) AssetOption {
+	return func(c *AssetChecker) {
+		c.httpClient = client
+	}
+}
+
+// WithAllowHTTP accepts plain http:// image URLs, e.g. from a local test server
+// Samsung only loads HTTPS images, so production cards should not need this
+func WithAllowHTTP() AssetOption {
+	return func(c *AssetChecker) {
+		c.allowHTTP = true
+	}
+}
+
+// WithAssetRule sets the rule of an image attribute, replacing the default
+func WithAssetRule(key string, rule AssetRule) AssetOption {
+	return func(c *AssetChecker) {
+		c.rules[key] = rule
+	}
+}
+
+// NewAssetChecker creates an asset checker with the default rules
+func NewAssetChecker(opts ...AssetOption) *AssetChecker {
+	c := &AssetChecker{
+		httpClient: &http.Client{Timeout: defaultAssetTimeout},
+		rules:      DefaultAssetRules(),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// assetInfo is what was learned from fetching one image
+type assetInfo struct {
+	err         error // Fetch or decode failure
+	contentType string
+	format      string
+	size        int64
+	truncated   bool // Body exceeded the largest rule size and was not read fully
+	width       int
+	height      int
+}
+
+// Check fetches every image URL of the card, including localized ones, and returns
+// the violations as ValidationErrors referencing the attribute
+func (c *AssetChecker) Check(ctx context.Context, walletCard WalletCard) []ValidationError {
+	v := &validator{}
+	fetched := make(map[string]*assetInfo)
+
+	for i, data := range walletCard.Card.Data {
+		path := fmt.Sprintf("card.data[%d]", i)
+		c.checkAttributes(ctx, v, path+".attributes", data.Attributes, fetched)
+		for j, localization := range data.Localization {
+			c.checkAttributes(ctx, v, fmt.Sprintf("%s.localization[%d].attributes", path, j), localization.Attributes, fetched)
+		}
+	}
+
+	return v.errors
+}
+
+// checkAttributes checks the image attributes of one attribute map in key order
+func (c *AssetChecker) checkAttributes(ctx context.Context, v *validator, path string, attributes map[string]interface{}, fetched map[string]*assetInfo) {
+	keys := make([]string, 0, len(c.rules))
+	for key := range c.rules {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		rawURL, _ := attributes[key].(string)
+		if rawURL == "" {
+			continue
+		}
+		field := attributePath(path, key)
+
+		u, err := url.Parse(rawURL)
+		if err != nil || u.Host == "" {
+			v.add(field, fmt.Sprintf("invalid image URL %q", rawURL))
+			continue
+		}
+		if u.Scheme != "https" && !(c.allowHTTP && u.Scheme == "http") {
+			v.add(field, fmt.Sprintf("image URL must use HTTPS: %s", rawURL))
+			continue
+		}
+
+		info, ok := fetched[rawURL]
+		if !ok {
+			info = c.fetch(ctx, rawURL)
+			fetched[rawURL] = info
+		}
+		c.checkRule(v, field, rawURL, info, c.rules[key])
+	}
+}
+
+// checkRule reports how an image violates a rule
+func (c *AssetChecker) checkRule(v *validator, field, rawURL string, info *assetInfo, rule AssetRule) {
+	if info.err != nil {
+		v.add(field, fmt.Sprintf("%s: %v", rawURL, info.err))
+		return
+	}
+
+	if info.truncated && rule.MaxBytes <= 0 {
+		// Unlimited rules still can't pass an image that was never read fully
+		v.add(field, fmt.Sprintf("%s: image is more than %d bytes, too large to check", rawURL, info.size))
+		return
+	}
+	if rule.MaxBytes > 0 && (info.truncated || info.size > rule.MaxBytes) {
+		size := fmt.Sprintf("%d bytes", info.size)
+		if info.truncated {
+			size = "more than " + size
+		}
+		v.add(field, fmt.Sprintf("%s: image is %s, maximum is %d", rawURL, size, rule.MaxBytes))
+	}
+	if info.truncated {
+		// Dimensions can't be checked without the full image
+		return
+	}
+
+	if (rule.MinWidth > 0 && info.width < rule.MinWidth) || (rule.MinHeight > 0 && info.height < rule.MinHeight) {
+		v.add(field, fmt.Sprintf("%s: image is %dx%d, minimum is %dx%d", rawURL, info.width, info.height, rule.MinWidth, rule.MinHeight))
+	}
+	if rule.AspectRatio > 0 && info.height > 0 {
+		tolerance := rule.AspectTolerance
+		if tolerance <= 0 {
+			tolerance = 0.05
+		}
+		ratio := float64(info.width) / float64(info.height)
+		if math.Abs(ratio-rule.AspectRatio)/rule.AspectRatio > tolerance {
+			v.add(field, fmt.Sprintf("%s: aspect ratio is %.2f (%dx%d), expected %.2f", rawURL, ratio, info.width, info.height, rule.AspectRatio))
+		}
+	}
+}
+
+// fetch downloads an image and decodes its format and dimensions
+func (c *AssetChecker) fetch(ctx context.Context, rawURL string) *assetInfo {
+	info := &assetInfo{}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		info.err = fmt.Errorf("failed to create request: %v", err)
+		return info
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		info.err = fmt.Errorf("image is unreachable: %v", err)
+		return info
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		info.err = fmt.Errorf("image request failed with status %d", resp.StatusCode)
+		return info
+	}
+
+	info.contentType, _, _ = mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	expectedFormat, ok := allowedImageTypes[strings.ToLower(info.contentType)]
+	if !ok {
+		info.err = fmt.Errorf("unsupported content type %q, expected PNG, JPEG or GIF", info.contentType)
+		return info
+	}
+
+	// Read at most one byte past the largest limit, so oversized images are detected
+	// without downloading them completely
+	limit := c.maxBytes()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		info.err = fmt.Errorf("failed to read image: %v", err)
+		return info
+	}
+	info.size = int64(len(body))
+	if info.size > limit {
+		info.size = limit
+		info.truncated = true
+		return info
+	}
+
+	config, format, err := image.DecodeConfig(bytes.NewReader(body))
+	if err != nil {
+		info.err = fmt.Errorf("failed to decode image: %v", err)
+		return info
+	}
+	if format != expectedFormat {
+		info.err = fmt.Errorf("content type %q does not match the %s image data", info.contentType, format)
+		return info
+	}
+	info.format = format
+	info.width = config.Width
+	info.height = config.Height
+
+	return info
+}
+
+// maxBytes returns the largest size limit of all rules, bounding each download
+func (c *AssetChecker) maxBytes() int64 {
+	var limit int64
+	for _, rule := range c.rules {
+		if rule.MaxBytes == 0 {
+			// Some images are unlimited; still cap downloads at a sane size
+			return maxAssetDownload
+		}
+		if rule.MaxBytes > limit {
+			limit = rule.MaxBytes
+		}
+	}
+	return limit
+}
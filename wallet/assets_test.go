@@ -0,0 +1,151 @@
+package wallet
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// pngImage returns a width x height PNG
+func pngImage(t *testing.T, width, height int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, width, height))); err != nil {
+		t.Fatalf("png.Encode() error = %v", err)
+	}
+	return buf.Bytes()
+}
+
+// assetServer serves body with contentType on every path
+func assetServer(t *testing.T, tls bool, contentType string, body []byte) *httptest.Server {
+	t.Helper()
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", contentType)
+		_, _ = w.Write(body)
+	})
+	server := httptest.NewUnstartedServer(handler)
+	if tls {
+		server.StartTLS()
+	} else {
+		server.Start()
+	}
+	t.Cleanup(server.Close)
+	return server
+}
+
+// imageCard returns a ticket whose only image attribute is key
+func imageCard(key, url string) WalletCard {
+	return WalletCard{Card: WalletCardBody{
+		Type: "ticket",
+		Data: []WalletCardData{{RefID: "ref-1", Attributes: WalletCardAttributes{key: url}}},
+	}}
+}
+
+// checkOne runs checker on a card with one image and returns the error messages
+func checkOne(checker *AssetChecker, key, url string) []string {
+	var messages []string
+	for _, err := range checker.Check(context.Background(), imageCard(key, url)) {
+		messages = append(messages, err.Message)
+	}
+	return messages
+}
+
+func TestAssetCheckerAcceptsValidImages(t *testing.T) {
+	server := assetServer(t, true, "image/png", pngImage(t, 64, 64))
+	checker := NewAssetChecker(WithAssetHTTPClient(server.Client()))
+
+	if errs := checkOne(checker, "logoImage", server.URL+"/logo.png"); errs != nil {
+		t.Errorf("Check() = %v, want no errors", errs)
+	}
+}
+
+func TestAssetCheckerContentType(t *testing.T) {
+	tests := map[string]struct {
+		contentType string
+		want        string
+	}{
+		"unsupported": {"image/webp", `unsupported content type "image/webp"`},
+		"missing":     {"", "unsupported content type"},
+		"mismatched":  {"image/jpeg", `content type "image/jpeg" does not match the png image data`},
+	}
+	for name, tt := range tests {
+		server := assetServer(t, true, tt.contentType, pngImage(t, 64, 64))
+		checker := NewAssetChecker(WithAssetHTTPClient(server.Client()))
+
+		errs := checkOne(checker, "logoImage", server.URL+"/logo.png")
+		if len(errs) != 1 || !strings.Contains(errs[0], tt.want) {
+			t.Errorf("%s: Check() = %v, want %q", name, errs, tt.want)
+		}
+	}
+}
+
+func TestAssetCheckerSize(t *testing.T) {
+	server := assetServer(t, true, "image/png", pngImage(t, 64, 64))
+	checker := NewAssetChecker(WithAssetHTTPClient(server.Client()),
+		WithAssetRule("logoImage", AssetRule{MaxBytes: 32, AspectRatio: 1}))
+
+	errs := checkOne(checker, "logoImage", server.URL+"/logo.png")
+	if len(errs) != 1 || !strings.Contains(errs[0], "maximum is 32") {
+		t.Errorf("Check() = %v, want a size error", errs)
+	}
+}
+
+func TestAssetCheckerUnlimitedRuleReportsOversizedImage(t *testing.T) {
+	// The body is cut off at the download cap, so it is never decoded
+	server := assetServer(t, true, "image/png", make([]byte, maxAssetDownload+1))
+	checker := NewAssetChecker(WithAssetHTTPClient(server.Client()), WithAssetRule("mainImg", AssetRule{}))
+
+	errs := checkOne(checker, "mainImg", server.URL+"/main.png")
+	if len(errs) != 1 || !strings.Contains(errs[0], "too large to check") {
+		t.Errorf("Check() = %v, want a too large to check error", errs)
+	}
+}
+
+func TestAssetCheckerAspectRatio(t *testing.T) {
+	tests := map[string]struct {
+		width, height int
+		wantErr       bool
+	}{
+		"square":          {64, 64, false},
+		"within 5%":       {66, 64, false},
+		"wide":            {128, 64, true},
+		"tall":            {64, 100, true},
+		"custom tolerant": {70, 64, false},
+	}
+	for name, tt := range tests {
+		server := assetServer(t, true, "image/png", pngImage(t, tt.width, tt.height))
+		opts := []AssetOption{WithAssetHTTPClient(server.Client())}
+		if name == "custom tolerant" {
+			opts = append(opts, WithAssetRule("logoImage", AssetRule{AspectRatio: 1, AspectTolerance: 0.1}))
+		}
+
+		errs := checkOne(NewAssetChecker(opts...), "logoImage", server.URL+"/logo.png")
+		if gotErr := len(errs) == 1 && strings.Contains(errs[0], "aspect ratio"); gotErr != tt.wantErr || (!tt.wantErr && errs != nil) {
+			t.Errorf("%s: Check() = %v, want aspect ratio error %v", name, errs, tt.wantErr)
+		}
+	}
+}
+
+func TestAssetCheckerHTTPS(t *testing.T) {
+	server := assetServer(t, false, "image/png", pngImage(t, 64, 64))
+	url := server.URL + "/logo.png"
+
+	errs := checkOne(NewAssetChecker(WithAssetHTTPClient(server.Client())), "logoImage", url)
+	if len(errs) != 1 || !strings.Contains(errs[0], "must use HTTPS") {
+		t.Errorf("Check() = %v, want an HTTPS error", errs)
+	}
+
+	if errs := checkOne(NewAssetChecker(WithAssetHTTPClient(server.Client()), WithAllowHTTP()), "logoImage", url); errs != nil {
+		t.Errorf("Check() with WithAllowHTTP = %v, want no errors", errs)
+	}
+
+	// WithAllowHTTP doesn't admit other schemes
+	if errs := checkOne(NewAssetChecker(WithAllowHTTP()), "logoImage", "ftp://example.com/logo.png"); len(errs) != 1 {
+		t.Errorf("Check(ftp) = %v, want an HTTPS error", errs)
+	}
+}
@@ -0,0 +1,301 @@
+package wallet
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/abyssparanoia/samsung-wallet-go/wallet/qrcode"
+)
+
+// Symbology is a barcode symbology, named by its Samsung ptSubFormat value
+type Symbology string
+
+const (
+	SymbologyQRCode       Symbology = "QR_CODE"
+	SymbologyPDF417       Symbology = "PDF_417"
+	SymbologyAztec        Symbology = "AZTEC"
+	SymbologyDataMatrix   Symbology = "DATA_MATRIX"
+	SymbologyCode128      Symbology = "CODE_128"
+	SymbologyCode39       Symbology = "CODE_39"
+	SymbologyEAN13        Symbology = "EAN_13"
+	SymbologyUPCA         Symbology = "UPC_A"
+	SymbologySerialNumber Symbology = "SERIAL" // Serial number shown as text, without a barcode
+)
+
+// ErrorCorrectionLevel is the error correction level of a QR code
+type ErrorCorrectionLevel string
+
+const (
+	ErrorCorrectionLow      ErrorCorrectionLevel = "L"
+	ErrorCorrectionMedium   ErrorCorrectionLevel = "M"
+	ErrorCorrectionQuartile ErrorCorrectionLevel = "Q"
+	ErrorCorrectionHigh     ErrorCorrectionLevel = "H"
+)
+
+// Samsung barcode.serialType and barcode.ptFormat values
+const (
+	serialTypeQRCode       = "QRCODE"
+	serialTypeBarcode      = "BARCODE"
+	serialTypeSerialNumber = "SERIALNUMBER"
+	ptFormatSerial         = "SERIAL"
+
+	// maxBarcodeValueLength is the maximum length of barcode.value
+	maxBarcodeValueLength = 4096
+)
+
+// symbologyInfo describes how a symbology maps to Samsung and legacy values
+type symbologyInfo struct {
+	serialType string // barcode.serialType
+	legacyType string // Barcode.Type
+}
+
+// symbologies lists the supported symbologies
+// The Samsung Wallet barcode format reference lists only QR_CODE under the QRCODE
+// serial type; every other symbology, 2D ones included, is a BARCODE
+var symbologies = map[Symbology]symbologyInfo{
+	SymbologyQRCode:       {serialTypeQRCode, "QR"},
+	SymbologyPDF417:       {serialTypeBarcode, "PDF417"},
+	SymbologyAztec:        {serialTypeBarcode, "Aztec"},
+	SymbologyDataMatrix:   {serialTypeBarcode, "DataMatrix"},
+	SymbologyCode128:      {serialTypeBarcode, "Code128"},
+	SymbologyCode39:       {serialTypeBarcode, "Code39"},
+	SymbologyEAN13:        {serialTypeBarcode, "EAN13"},
+	SymbologyUPCA:         {serialTypeBarcode, "UPCA"},
+	SymbologySerialNumber: {serialTypeSerialNumber, "Serial"},
+}
+
+// BarcodeSpec is a typed barcode that maps to the barcode.* card attributes
+type BarcodeSpec struct {
+	Symbology            Symbology            // Barcode symbology
+	Value                string               // Encoded value
+	ShowSerial           bool                 // Also show the value as text below the barcode
+	ErrorCorrectionLevel ErrorCorrectionLevel // QR codes only; empty leaves the choice to Samsung Wallet
+}
+
+// symbologyAliases maps other common names to symbologies
+var symbologyAliases = map[string]Symbology{
+	"QRCODE":       SymbologyQRCode,
+	"SERIALNUMBER": SymbologySerialNumber,
+}
+
+// ParseSymbology parses a Samsung ptSubFormat value or a legacy Barcode type name,
+// e.g. "QR_CODE", "QR" or "Code128"
+func ParseSymbology(s string) (Symbology, error) {
+	normalized := strings.ToUpper(strings.NewReplacer("_", "", "-", "", " ", "").Replace(s))
+	if symbology, ok := symbologyAliases[normalized]; ok {
+		return symbology, nil
+	}
+	for symbology, info := range symbologies {
+		if normalized == strings.ReplaceAll(string(symbology), "_", "") || normalized == strings.ToUpper(info.legacyType) {
+			return symbology, nil
+		}
+	}
+	return "", fmt.Errorf("unsupported barcode symbology: %q", s)
+}
+
+// Validate checks the value against the charset and length of the symbology
+func (s BarcodeSpec) Validate() error {
+	if _, ok := symbologies[s.Symbology]; !ok {
+		return fmt.Errorf("unsupported barcode symbology: %q", s.Symbology)
+	}
+	if s.Value == "" {
+		return errors.New("barcode value is required")
+	}
+	if n := utf8.RuneCountInString(s.Value); n > maxBarcodeValueLength {
+		return fmt.Errorf("barcode value must be at most %d characters, got %d", maxBarcodeValueLength, n)
+	}
+	if s.ErrorCorrectionLevel != "" {
+		if s.Symbology != SymbologyQRCode {
+			return fmt.Errorf("error correction level only applies to QR codes, not %s", s.Symbology)
+		}
+		if _, err := qrcode.ParseLevel(string(s.ErrorCorrectionLevel)); err != nil {
+			return fmt.Errorf("invalid error correction level %q", s.ErrorCorrectionLevel)
+		}
+	}
+
+	switch s.Symbology {
+	case SymbologyQRCode:
+		level := qrcode.Low
+		if s.ErrorCorrectionLevel != "" {
+			level, _ = qrcode.ParseLevel(string(s.ErrorCorrectionLevel))
+		}
+		if _, err := qrcode.Encode(s.Value, level); err != nil {
+			return fmt.Errorf("barcode value does not fit in a QR code at level %s", level)
+		}
+	case SymbologyPDF417:
+		return checkCapacity(s, 1850, 1108)
+	case SymbologyAztec:
+		return checkCapacity(s, 3067, 1914)
+	case SymbologyDataMatrix:
+		return checkCapacity(s, 2335, 1556)
+	case SymbologyCode128:
+		for _, r := range s.Value {
+			if r > 127 {
+				return fmt.Errorf("CODE_128 values must be ASCII, got %q", r)
+			}
+		}
+		if len(s.Value) > 80 {
+			return fmt.Errorf("CODE_128 values must be at most 80 characters, got %d", len(s.Value))
+		}
+	case SymbologyCode39:
+		const charset = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ -.$/+%"
+		for _, r := range s.Value {
+			if !strings.ContainsRune(charset, r) {
+				return fmt.Errorf("CODE_39 values may only contain 0-9, A-Z, space and -.$/+%%, got %q", r)
+			}
+		}
+		if len(s.Value) > 43 {
+			return fmt.Errorf("CODE_39 values must be at most 43 characters, got %d", len(s.Value))
+		}
+	case SymbologyEAN13:
+		return checkGTIN(s, 13)
+	case SymbologyUPCA:
+		return checkGTIN(s, 12)
+	}
+	return nil
+}
+
+// checkCapacity checks a 2D symbology's capacity for ASCII text and binary data
+func checkCapacity(s BarcodeSpec, maxText, maxBytes int) error {
+	ascii := strings.IndexFunc(s.Value, func(r rune) bool { return r > 127 }) < 0
+	if ascii && len(s.Value) > maxText {
+		return fmt.Errorf("%s values must be at most %d characters, got %d", s.Symbology, maxText, len(s.Value))
+	}
+	if !ascii && len(s.Value) > maxBytes {
+		return fmt.Errorf("%s values with non-ASCII text must be at most %d bytes, got %d", s.Symbology, maxBytes, len(s.Value))
+	}
+	return nil
+}
+
+// checkGTIN checks an EAN-13 or UPC-A value of length digits, with or without its check digit
+func checkGTIN(s BarcodeSpec, length int) error {
+	for _, r := range s.Value {
+		if r < '0' || r > '9' {
+			return fmt.Errorf("%s values must be digits only, got %q", s.Symbology, r)
+		}
+	}
+	switch len(s.Value) {
+	case length - 1:
+		return nil
+	case length:
+		if want := gtinCheckDigit(s.Value[:length-1]); s.Value[length-1] != want {
+			return fmt.Errorf("%s check digit is %c, expected %c", s.Symbology, s.Value[length-1], want)
+		}
+		return nil
+	default:
+		return fmt.Errorf("%s values must have %d digits (or %d without check digit), got %d", s.Symbology, length, length-1, len(s.Value))
+	}
+}
+
+// gtinCheckDigit computes the GS1 check digit of digits
+func gtinCheckDigit(digits string) byte {
+	sum := 0
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		// Weights alternate 3, 1 starting from the rightmost digit
+		if (len(digits)-1-i)%2 == 0 {
+			d *= 3
+		}
+		sum += d
+	}
+	return byte('0' + (10-sum%10)%10)
+}
+
+// SerialType returns the barcode.serialType value
+func (s BarcodeSpec) SerialType() string {
+	return symbologies[s.Symbology].serialType
+}
+
+// PTFormat returns the barcode.ptFormat value
+func (s BarcodeSpec) PTFormat() string {
+	serialType := s.SerialType()
+	switch {
+	case serialType == serialTypeSerialNumber:
+		return ptFormatSerial
+	case s.ShowSerial:
+		return serialType + ptFormatSerial
+	default:
+		return serialType
+	}
+}
+
+// PTSubFormat returns the barcode.ptSubFormat value; empty for serial numbers
+func (s BarcodeSpec) PTSubFormat() string {
+	if s.Symbology == SymbologySerialNumber {
+		return ""
+	}
+	return string(s.Symbology)
+}
+
+// Attributes returns the barcode.* card attributes
+func (s BarcodeSpec) Attributes() map[string]string {
+	attributes := map[string]string{
+		"barcode.value":      s.Value,
+		"barcode.serialType": s.SerialType(),
+		"barcode.ptFormat":   s.PTFormat(),
+	}
+	if subFormat := s.PTSubFormat(); subFormat != "" {
+		attributes["barcode.ptSubFormat"] = subFormat
+	}
+	if s.ErrorCorrectionLevel != "" {
+		attributes["barcode.errorCorrectionLevel"] = string(s.ErrorCorrectionLevel)
+	}
+	return attributes
+}
+
+// Legacy returns the barcode as a legacy Barcode
+func (s BarcodeSpec) Legacy() *Barcode {
+	barcode := &Barcode{
+		Type:   symbologies[s.Symbology].legacyType,
+		Value:  s.Value,
+		Format: s.PTFormat(),
+	}
+	if s.ShowSerial {
+		barcode.AltText = s.Value
+	}
+	return barcode
+}
+
+// BarcodeSpecFromLegacy converts a legacy Barcode to a BarcodeSpec
+func BarcodeSpecFromLegacy(barcode *Barcode) (BarcodeSpec, error) {
+	if barcode == nil {
+		return BarcodeSpec{}, errors.New("barcode is nil")
+	}
+	symbology, err := ParseSymbology(barcode.Type)
+	if err != nil {
+		return BarcodeSpec{}, err
+	}
+	return BarcodeSpec{
+		Symbology:  symbology,
+		Value:      barcode.Value,
+		ShowSerial: barcode.AltText != "" || strings.HasSuffix(barcode.Format, ptFormatSerial),
+	}, nil
+}
+
+// barcodeSpecFromAttributes reads a BarcodeSpec from card attributes, if they define one
+func barcodeSpecFromAttributes(attributes map[string]interface{}) (BarcodeSpec, bool) {
+	value, _ := attributes["barcode.value"].(string)
+	serialType, _ := attributes["barcode.serialType"].(string)
+	subFormat, _ := attributes["barcode.ptSubFormat"].(string)
+	ptFormat, _ := attributes["barcode.ptFormat"].(string)
+	level, _ := attributes["barcode.errorCorrectionLevel"].(string)
+	if value == "" {
+		return BarcodeSpec{}, false
+	}
+
+	symbology := Symbology(subFormat)
+	if serialType == serialTypeSerialNumber {
+		symbology = SymbologySerialNumber
+	}
+	if _, ok := symbologies[symbology]; !ok {
+		return BarcodeSpec{}, false
+	}
+	return BarcodeSpec{
+		Symbology:            symbology,
+		Value:                value,
+		ShowSerial:           strings.HasSuffix(ptFormat, ptFormatSerial),
+		ErrorCorrectionLevel: ErrorCorrectionLevel(level),
+	}, true
+}
@@ -0,0 +1,139 @@
+package wallet
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBuildStrictReportsBarcodeOnce(t *testing.T) {
+	tests := map[string]struct {
+		spec BarcodeSpec
+		want string
+	}{
+		"check digit":   {BarcodeSpec{Symbology: SymbologyEAN13, Value: "4006381333932"}, "check digit is 2, expected 1"},
+		"empty value":   {BarcodeSpec{Symbology: SymbologyCode128}, "is required when barcode.serialType is set"},
+		"serial number": {BarcodeSpec{Symbology: SymbologySerialNumber}, "is required when barcode.serialType is set"},
+		"symbology":     {BarcodeSpec{Symbology: "MAXICODE", Value: "x"}, `got "MAXICODE"`},
+		"level":         {BarcodeSpec{Symbology: SymbologyPDF417, Value: "x", ErrorCorrectionLevel: ErrorCorrectionHigh}, "only applies to QR codes"},
+	}
+	for name, tt := range tests {
+		_, err := newTestTicket().SetBarcodeSpec(tt.spec).BuildStrict()
+		if err == nil {
+			t.Errorf("%s: BuildStrict() succeeded", name)
+			continue
+		}
+		if got := strings.Count(err.Error(), "\n") + 1; got != 1 || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: BuildStrict() error = %q, want one error containing %q", name, err, tt.want)
+		}
+	}
+
+	if _, err := newTestTicket().SetBarcodeSpec(BarcodeSpec{Symbology: SymbologyEAN13, Value: "4006381333931"}).BuildStrict(); err != nil {
+		t.Errorf("BuildStrict() with a valid barcode error = %v", err)
+	}
+}
+
+func TestBarcodeSpecAttributes(t *testing.T) {
+	tests := []struct {
+		spec                            BarcodeSpec
+		serialType, ptFormat, subFormat string
+	}{
+		{BarcodeSpec{Symbology: SymbologyQRCode, Value: "x"}, "QRCODE", "QRCODE", "QR_CODE"},
+		{BarcodeSpec{Symbology: SymbologyQRCode, Value: "x", ShowSerial: true}, "QRCODE", "QRCODESERIAL", "QR_CODE"},
+		{BarcodeSpec{Symbology: SymbologyAztec, Value: "x"}, "BARCODE", "BARCODE", "AZTEC"},
+		{BarcodeSpec{Symbology: SymbologyDataMatrix, Value: "x", ShowSerial: true}, "BARCODE", "BARCODESERIAL", "DATA_MATRIX"},
+		{BarcodeSpec{Symbology: SymbologyPDF417, Value: "x"}, "BARCODE", "BARCODE", "PDF_417"},
+		{BarcodeSpec{Symbology: SymbologyCode128, Value: "x"}, "BARCODE", "BARCODE", "CODE_128"},
+		{BarcodeSpec{Symbology: SymbologySerialNumber, Value: "x", ShowSerial: true}, "SERIALNUMBER", "SERIAL", ""},
+	}
+	for _, tt := range tests {
+		attributes := tt.spec.Attributes()
+		if attributes["barcode.serialType"] != tt.serialType || attributes["barcode.ptFormat"] != tt.ptFormat || attributes["barcode.ptSubFormat"] != tt.subFormat {
+			t.Errorf("%s Attributes() = %v, want serialType %s, ptFormat %s, ptSubFormat %q",
+				tt.spec.Symbology, attributes, tt.serialType, tt.ptFormat, tt.subFormat)
+		}
+
+		// The attributes read back as the same spec
+		values := make(map[string]interface{}, len(attributes))
+		for key, value := range attributes {
+			values[key] = value
+		}
+		if got, ok := barcodeSpecFromAttributes(values); !ok || got != tt.spec {
+			t.Errorf("barcodeSpecFromAttributes(%v) = %+v, want %+v", attributes, got, tt.spec)
+		}
+	}
+}
+
+func TestGTINCheckDigit(t *testing.T) {
+	tests := map[string]byte{
+		"400638133393": '1', // EAN-13 4006381333931
+		"590123412345": '7', // EAN-13 5901234123457
+		"978030640615": '7', // ISBN-13 9780306406157
+		"03600029145":  '2', // UPC-A 036000291452
+		"00000000000":  '0',
+		"12345678901":  '2', // UPC-A 123456789012
+	}
+	for digits, want := range tests {
+		if got := gtinCheckDigit(digits); got != want {
+			t.Errorf("gtinCheckDigit(%s) = %c, want %c", digits, got, want)
+		}
+	}
+}
+
+func TestBarcodeSpecValidate(t *testing.T) {
+	valid := []BarcodeSpec{
+		{Symbology: SymbologyEAN13, Value: "4006381333931"},
+		{Symbology: SymbologyEAN13, Value: "400638133393"},
+		{Symbology: SymbologyUPCA, Value: "036000291452"},
+		{Symbology: SymbologyUPCA, Value: "03600029145"},
+		{Symbology: SymbologyCode39, Value: "ABC-123 $/+%."},
+		{Symbology: SymbologyCode128, Value: "Ticket #42"},
+		{Symbology: SymbologyQRCode, Value: "https://example.com", ErrorCorrectionLevel: ErrorCorrectionHigh},
+		{Symbology: SymbologyAztec, Value: strings.Repeat("x", 3067)},
+		{Symbology: SymbologySerialNumber, Value: "A-0001"},
+	}
+	for _, spec := range valid {
+		if err := spec.Validate(); err != nil {
+			t.Errorf("Validate(%s %q) error = %v", spec.Symbology, spec.Value, err)
+		}
+	}
+
+	invalid := []BarcodeSpec{
+		{Symbology: SymbologyEAN13, Value: "4006381333932"},
+		{Symbology: SymbologyEAN13, Value: "40063813339"},
+		{Symbology: SymbologyEAN13, Value: "400638133393A"},
+		{Symbology: SymbologyUPCA, Value: "036000291453"},
+		{Symbology: SymbologyCode39, Value: "abc"},
+		{Symbology: SymbologyCode128, Value: "café"},
+		{Symbology: SymbologyCode128, Value: strings.Repeat("x", 81)},
+		{Symbology: SymbologyQRCode, Value: strings.Repeat("x", 2954)},
+		{Symbology: SymbologyQRCode, Value: "x", ErrorCorrectionLevel: "X"},
+		{Symbology: SymbologyDataMatrix, Value: strings.Repeat("é", 779)},
+		{Symbology: SymbologyPDF417, Value: ""},
+	}
+	for _, spec := range invalid {
+		if err := spec.Validate(); err == nil {
+			t.Errorf("Validate(%s %q) succeeded", spec.Symbology, spec.Value)
+		}
+	}
+}
+
+func TestParseSymbology(t *testing.T) {
+	tests := map[string]Symbology{
+		"QR_CODE":      SymbologyQRCode,
+		"qr":           SymbologyQRCode,
+		"QRCODE":       SymbologyQRCode,
+		"Code128":      SymbologyCode128,
+		"code-39":      SymbologyCode39,
+		"Data Matrix":  SymbologyDataMatrix,
+		"EAN13":        SymbologyEAN13,
+		"SERIALNUMBER": SymbologySerialNumber,
+	}
+	for s, want := range tests {
+		if got, err := ParseSymbology(s); err != nil || got != want {
+			t.Errorf("ParseSymbology(%q) = %q, %v, want %q", s, got, err, want)
+		}
+	}
+	if _, err := ParseSymbology("MaxiCode"); err == nil {
+		t.Error("ParseSymbology(MaxiCode) succeeded")
+	}
+}
@@ -0,0 +1,222 @@
+package wallet
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/url"
+	"strings"
+)
+
+// ButtonTheme selects the color scheme of the Add to Samsung Wallet button
+type ButtonTheme string
+
+const (
+	ButtonThemeLight ButtonTheme = "light" // Dark button for light backgrounds
+	ButtonThemeDark  ButtonTheme = "dark"  // Light button for dark backgrounds
+)
+
+// ButtonFormat selects where the button snippet is embedded
+type ButtonFormat string
+
+const (
+	ButtonFormatHTML  ButtonFormat = "html"  // Web pages, rendered when the page is viewed
+	ButtonFormatEmail ButtonFormat = "email" // HTML email, inline styles only
+	ButtonFormatAMP   ButtonFormat = "amp"   // AMP for Email
+)
+
+// defaultButtonLocale is used when no label exists for the requested locale
+const defaultButtonLocale = "en"
+
+// ErrDataTransmitLink is returned when an email button is rendered for a data transmit link,
+// whose CDATA expires long before the message is opened
+var ErrDataTransmitLink = errors.New("data transmit links expire within seconds and cannot be used in email; use a data fetch link")
+
+// buttonLabels holds the button text by language
+var buttonLabels = map[string]string{
+	"en": "Add to Samsung Wallet",
+	"ko": "삼성 월렛에 추가",
+	"ja": "Samsung Walletに追加",
+	"zh": "添加到 Samsung Wallet",
+	"de": "Zu Samsung Wallet hinzufügen",
+	"fr": "Ajouter à Samsung Wallet",
+	"es": "Añadir a Samsung Wallet",
+	"it": "Aggiungi a Samsung Wallet",
+	"pt": "Adicionar à Samsung Wallet",
+}
+
+// buttonColors holds the background, text and border colors of each theme
+var buttonColors = map[ButtonTheme][3]string{
+	ButtonThemeLight: {"#000000", "#ffffff", "#000000"},
+	ButtonThemeDark:  {"#ffffff", "#000000", "#ffffff"},
+}
+
+// ButtonOptions configures an Add to Samsung Wallet button
+type ButtonOptions struct {
+	Locale            string      // BCP 47 locale of the label, e.g. "ko-KR" (default "en")
+	Theme             ButtonTheme // Color scheme (default ButtonThemeLight)
+	ImageURL          string      // Optional button artwork from Samsung's brand guidelines; the label becomes its alt text
+	ImageWidth        int         // Artwork width in pixels (default 200)
+	ImageHeight       int         // Artwork height in pixels (default 48)
+	AllowDataTransmit bool        // Allow data transmit links in email formats
+}
+
+// ButtonOption configures ButtonOptions
+type ButtonOption func(*ButtonOptions)
+
+// WithButtonLocale sets the locale of the button label
+func WithButtonLocale(locale string) ButtonOption {
+	return func(o *ButtonOptions) {
+		o.Locale = locale
+	}
+}
+
+// WithButtonTheme sets the button color scheme
+func WithButtonTheme(theme ButtonTheme) ButtonOption {
+	return func(o *ButtonOptions) {
+		o.Theme = theme
+	}
+}
+
+// WithButtonImage renders the button as an image instead of a styled link
+func WithButtonImage(imageURL string, width, height int) ButtonOption {
+	return func(o *ButtonOptions) {
+		o.ImageURL = imageURL
+		o.ImageWidth = width
+		o.ImageHeight = height
+	}
+}
+
+// AllowDataTransmit permits data transmit links in email formats, e.g. for transactional
+// mail that is known to be opened immediately
+func AllowDataTransmit() ButtonOption {
+	return func(o *ButtonOptions) {
+		o.AllowDataTransmit = true
+	}
+}
+
+// buttonData is the template input of a button snippet
+type buttonData struct {
+	URL         string
+	Label       string
+	Lang        string
+	Background  string
+	Color       string
+	Border      string
+	ImageURL    string
+	ImageWidth  int
+	ImageHeight int
+}
+
+// buttonTemplates holds the snippet template of each format
+// Email clients strip <style> blocks and ignore classes, so every format uses inline styles
+var buttonTemplates = map[ButtonFormat]*template.Template{
+	ButtonFormatHTML: template.Must(template.New("html").Parse(
+		`<a href="{{.URL}}" lang="{{.Lang}}" role="button" aria-label="{{.Label}}" target="_blank" rel="noopener"` +
+			` style="display:inline-block;{{if not .ImageURL}}padding:12px 24px;border:1px solid {{.Border}};border-radius:24px;background-color:{{.Background}};color:{{.Color}};font-family:Arial,Helvetica,sans-serif;font-size:16px;font-weight:bold;line-height:24px;{{end}}text-decoration:none;">` +
+			`{{if .ImageURL}}<img src="{{.ImageURL}}" alt="{{.Label}}" width="{{.ImageWidth}}" height="{{.ImageHeight}}" style="display:block;border:0;">{{else}}{{.Label}}{{end}}</a>`)),
+	ButtonFormatEmail: template.Must(template.New("email").Parse(
+		`<table role="presentation" border="0" cellpadding="0" cellspacing="0" style="border-collapse:separate;"><tr>` +
+			`<td align="center"{{if not .ImageURL}} bgcolor="{{.Background}}" style="border:1px solid {{.Border}};border-radius:24px;"{{end}}>` +
+			`<a href="{{.URL}}" lang="{{.Lang}}" target="_blank"` +
+			` style="display:inline-block;{{if not .ImageURL}}padding:12px 24px;color:{{.Color}};font-family:Arial,Helvetica,sans-serif;font-size:16px;font-weight:bold;line-height:24px;{{end}}text-decoration:none;">` +
+			`{{if .ImageURL}}<img src="{{.ImageURL}}" alt="{{.Label}}" width="{{.ImageWidth}}" height="{{.ImageHeight}}" style="display:block;border:0;">{{else}}{{.Label}}{{end}}</a>` +
+			`</td></tr></table>`)),
+	ButtonFormatAMP: template.Must(template.New("amp").Parse(
+		`<a href="{{.URL}}" lang="{{.Lang}}" target="_blank"` +
+			` style="display:inline-block;{{if not .ImageURL}}padding:12px 24px;border:1px solid {{.Border}};border-radius:24px;background-color:{{.Background}};color:{{.Color}};font-family:Arial,Helvetica,sans-serif;font-size:16px;font-weight:bold;line-height:24px;{{end}}text-decoration:none;">` +
+			`{{if .ImageURL}}<amp-img src="{{.ImageURL}}" alt="{{.Label}}" width="{{.ImageWidth}}" height="{{.ImageHeight}}" layout="fixed"></amp-img>{{else}}{{.Label}}{{end}}</a>`)),
+}
+
+// RenderButton renders an Add to Samsung Wallet button for a link as an HTML snippet
+// Email and AMP formats refuse data transmit links unless AllowDataTransmit is given
+func RenderButton(link *ATWLink, format ButtonFormat, opts ...ButtonOption) (string, error) {
+	if link == nil || link.URL == "" {
+		return "", fmt.Errorf("link is required")
+	}
+
+	tmpl, ok := buttonTemplates[format]
+	if !ok {
+		return "", fmt.Errorf("unsupported button format: %s", format)
+	}
+
+	options := ButtonOptions{
+		Locale: defaultButtonLocale,
+		Theme:  ButtonThemeLight,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	if format != ButtonFormatHTML && isDataTransmitLink(link) && !options.AllowDataTransmit {
+		return "", ErrDataTransmitLink
+	}
+
+	colors, ok := buttonColors[options.Theme]
+	if !ok {
+		return "", fmt.Errorf("unsupported button theme: %s", options.Theme)
+	}
+
+	if options.ImageURL != "" {
+		if options.ImageWidth <= 0 {
+			options.ImageWidth = 200
+		}
+		if options.ImageHeight <= 0 {
+			options.ImageHeight = 48
+		}
+	}
+
+	lang, label := buttonLabel(options.Locale)
+	data := buttonData{
+		URL:         link.URL,
+		Label:       label,
+		Lang:        lang,
+		Background:  colors[0],
+		Color:       colors[1],
+		Border:      colors[2],
+		ImageURL:    options.ImageURL,
+		ImageWidth:  options.ImageWidth,
+		ImageHeight: options.ImageHeight,
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render button: %v", err)
+	}
+	return buf.String(), nil
+}
+
+// isDataTransmitLink reports whether a link carries CDATA, judging by its URL as well
+// as its Type so hand-built links are caught too
+func isDataTransmitLink(link *ATWLink) bool {
+	if link.Type == LinkTypeDataTransmit || link.CDATA != "" {
+		return true
+	}
+
+	u, err := url.Parse(link.URL)
+	if err != nil {
+		return strings.Contains(link.URL, linkParamCDATA+"=")
+	}
+	// Parameters may follow the fragment name, e.g. #Clip?cdata=..., or sit in the query
+	_, fragmentParams, _ := strings.Cut(u.EscapedFragment(), "?")
+	for _, rawParams := range []string{u.RawQuery, fragmentParams} {
+		// ParseQuery keeps the parameters it could parse, so malformed ones don't hide cdata
+		params, _ := url.ParseQuery(rawParams)
+		if params.Has(linkParamCDATA) {
+			return true
+		}
+	}
+	return false
+}
+
+// buttonLabel returns the language tag and label for a locale, falling back to
+// the base language and then to English
+func buttonLabel(locale string) (string, string) {
+	locale = strings.ReplaceAll(strings.TrimSpace(locale), "_", "-")
+	base := strings.ToLower(strings.SplitN(locale, "-", 2)[0])
+	if label, ok := buttonLabels[base]; ok {
+		return locale, label
+	}
+	return defaultButtonLocale, buttonLabels[defaultButtonLocale]
+}
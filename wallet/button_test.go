@@ -0,0 +1,46 @@
+package wallet
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRenderButtonRefusesDataTransmitLinks(t *testing.T) {
+	links := map[string]*ATWLink{
+		"typed":          {URL: "https://a.swallet.link/atw/v3/card#Clip?cdata=token", Type: LinkTypeDataTransmit},
+		"untyped":        {URL: "https://a.swallet.link/atw/v3/card#Clip?cdata=token"},
+		"query":          {URL: "https://a.swallet.link/atw/v3/card?cdata=token"},
+		"malformed":      {URL: "https://a.swallet.link/atw/v3/card#Clip?x=%zz&cdata=token"},
+		"cdata field":    {URL: "https://example.com/add", CDATA: "token"},
+		"with callback":  {URL: "https://a.swallet.link/atw/v3/card#Clip?callbackUrl=https%3A%2F%2Fexample.com&cdata=token"},
+		"unparsable url": {URL: "https://a.swallet.link/%zz#Clip?cdata=token"},
+	}
+	for name, link := range links {
+		for _, format := range []ButtonFormat{ButtonFormatEmail, ButtonFormatAMP} {
+			if _, err := RenderButton(link, format); !errors.Is(err, ErrDataTransmitLink) {
+				t.Errorf("RenderButton(%s, %s) error = %v, want ErrDataTransmitLink", name, format, err)
+			}
+		}
+		if _, err := RenderButton(link, ButtonFormatEmail, AllowDataTransmit()); err != nil {
+			t.Errorf("RenderButton(%s) with AllowDataTransmit error = %v", name, err)
+		}
+	}
+}
+
+func TestRenderButtonDataFetchLink(t *testing.T) {
+	link := &ATWLink{URL: "https://a.swallet.link/atw/v3/cert/card#Clip?pdata=ref-1"}
+
+	for _, format := range []ButtonFormat{ButtonFormatHTML, ButtonFormatEmail, ButtonFormatAMP} {
+		html, err := RenderButton(link, format, WithButtonLocale("ko-KR"), WithButtonTheme(ButtonThemeDark))
+		if err != nil {
+			t.Fatalf("RenderButton(%s) error = %v", format, err)
+		}
+		if !strings.Contains(html, `href="https://a.swallet.link/atw/v3/cert/card#Clip?pdata=ref-1"`) {
+			t.Errorf("RenderButton(%s) does not link to the data fetch link: %s", format, html)
+		}
+		if !strings.Contains(html, `lang="ko-KR"`) {
+			t.Errorf("RenderButton(%s) is not in Korean: %s", format, html)
+		}
+	}
+}
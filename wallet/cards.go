@@ -2,6 +2,7 @@ package wallet
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 )
@@ -12,6 +13,11 @@ import (
 type EventTicketBuilder struct {
 	walletCard WalletCard
 	attributes TicketAttributes
+	errs       []error // Errors from setters, reported by BuildStrict
+
+	sanitizeNotices bool            // Apply SanitizeNotice to notice fields in Build
+	strictContrast  bool            // Report CheckContrast warnings from BuildStrict
+	relatedCoupons  []RelatedCoupon // Coupons added with AddRelatedCoupon
 }
 
 // NewEventTicket creates a new event ticket builder using official Samsung Wallet structure
@@ -141,16 +147,16 @@ func (b *EventTicketBuilder) SetTicketInfo(reservationNumber, user, certificatio
 	return b
 }
 
-// SetDates sets issue, start, and end dates
+// SetDates sets issue, start, and end dates with their UTC offsets
 func (b *EventTicketBuilder) SetDates(issueDate, startDate, endDate *time.Time) *EventTicketBuilder {
 	if issueDate != nil {
-		b.attributes.IssueDate = issueDate.UnixMilli()
+		b.SetIssueDate(*issueDate)
 	}
 	if startDate != nil {
-		b.attributes.StartDate = startDate.UnixMilli()
+		b.SetStartDate(*startDate)
 	}
 	if endDate != nil {
-		b.attributes.EndDate = endDate.UnixMilli()
+		b.SetEndDate(*endDate)
 	}
 	return b
 }
@@ -170,19 +176,26 @@ func (b *EventTicketBuilder) SetHolderInfo(holderName, photoBase64, photoFormat
 }
 
 // SetStyling sets visual styling options
+// Hex colors are normalized to #RRGGBB, so the short form #RGB is accepted
 func (b *EventTicketBuilder) SetStyling(bgColor, fontColor, blinkColor string) *EventTicketBuilder {
 	if bgColor != "" {
-		b.attributes.BGColor = bgColor
+		b.attributes.BGColor = normalizeColor(bgColor)
 	}
 	if fontColor != "" {
-		b.attributes.FontColor = fontColor
+		b.attributes.FontColor = normalizeColor(fontColor)
 	}
 	if blinkColor != "" {
-		b.attributes.BlinkColor = blinkColor
+		b.attributes.BlinkColor = normalizeColor(blinkColor)
 	}
 	return b
 }
 
+// SetStrictContrast makes BuildStrict report CheckContrast warnings as errors
+func (b *EventTicketBuilder) SetStrictContrast(enabled bool) *EventTicketBuilder {
+	b.strictContrast = enabled
+	return b
+}
+
 // SetBarcode sets barcode information
 func (b *EventTicketBuilder) SetBarcode(value, serialType, ptFormat, ptSubFormat string) *EventTicketBuilder {
 	if value != "" {
@@ -200,6 +213,37 @@ func (b *EventTicketBuilder) SetBarcode(value, serialType, ptFormat, ptSubFormat
 	return b
 }
 
+// SetBarcodeSpec sets the barcode from a typed spec, replacing any barcode fields set before
+// An invalid spec is still applied and reported by Validate and BuildStrict
+func (b *EventTicketBuilder) SetBarcodeSpec(spec BarcodeSpec) *EventTicketBuilder {
+	b.attributes.BarcodeValue = spec.Value
+	b.attributes.BarcodeSerialType = spec.SerialType()
+	b.attributes.BarcodePTFormat = spec.PTFormat()
+	b.attributes.BarcodePTSubFormat = spec.PTSubFormat()
+	b.attributes.BarcodeErrorCorrLevel = string(spec.ErrorCorrectionLevel)
+	return b
+}
+
+// SetRotatingBarcode sets a rotating QR code: the current value, barcode.interval and the
+// provision.* attributes the device uses to compute the following values
+func (b *EventTicketBuilder) SetRotatingBarcode(rotating *RotatingBarcode) *EventTicketBuilder {
+	if rotating == nil {
+		b.errs = append(b.errs, fmt.Errorf("rotating barcode is required"))
+		return b
+	}
+	if err := rotating.validate(); err != nil {
+		b.errs = append(b.errs, err)
+		return b
+	}
+	b.SetBarcodeSpec(BarcodeSpec{Symbology: SymbologyQRCode, Value: rotating.Value(time.Now())})
+
+	attributes := rotating.Attributes()
+	b.attributes.BarcodeInterval = attributes["barcode.interval"]
+	b.attributes.ProvisionData = attributes["provision.data"]
+	b.attributes.ProvisionInterval = attributes["provision.interval"]
+	return b
+}
+
 // SetQRCode is a convenience method to set QR code barcode
 func (b *EventTicketBuilder) SetQRCode(value string) *EventTicketBuilder {
 	return b.SetBarcode(value, "QRCODE", "QRCODESERIAL", "QR_CODE")
@@ -220,9 +264,12 @@ func (b *EventTicketBuilder) SetPersonInfoFromStruct(persons []PersonInfo) *Even
 			Person: persons,
 		}
 
-		if jsonData, err := json.Marshal(personData); err == nil {
-			b.attributes.Person1 = string(jsonData)
+		jsonData, err := json.Marshal(personData)
+		if err != nil {
+			b.errs = append(b.errs, fmt.Errorf("failed to marshal person info: %v", err))
+			return b
 		}
+		b.attributes.Person1 = string(jsonData)
 	}
 	return b
 }
@@ -241,9 +288,12 @@ func (b *EventTicketBuilder) SetLocations(locationsJSON string) *EventTicketBuil
 
 // SetLocationsFromStruct sets location information from structs
 func (b *EventTicketBuilder) SetLocationsFromStruct(locations []TicketLocation) *EventTicketBuilder {
-	if jsonData, err := json.Marshal(locations); err == nil {
-		b.attributes.Locations = string(jsonData)
+	jsonData, err := json.Marshal(locations)
+	if err != nil {
+		b.errs = append(b.errs, fmt.Errorf("failed to marshal locations: %v", err))
+		return b
 	}
+	b.attributes.Locations = string(jsonData)
 	return b
 }
 
@@ -261,6 +311,14 @@ func (b *EventTicketBuilder) SetNoticeDescription(noticeHTML string) *EventTicke
 	return b
 }
 
+// SetNoticeSanitization enables or disables sanitizing noticeDesc and the related coupon
+// notices, base and localized, with SanitizeNotice when the card is built, e.g. for
+// user-generated text
+func (b *EventTicketBuilder) SetNoticeSanitization(enabled bool) *EventTicketBuilder {
+	b.sanitizeNotices = enabled
+	return b
+}
+
 // SetGroupInfo sets group information fields
 func (b *EventTicketBuilder) SetGroupInfo(groupInfo1, groupInfo2, groupInfo3 string) *EventTicketBuilder {
 	if groupInfo1 != "" {
@@ -283,9 +341,12 @@ func (b *EventTicketBuilder) SetCustomerServiceInfo(csInfoJSON string) *EventTic
 
 // SetCustomerServiceInfoFromStruct sets customer service information from struct
 func (b *EventTicketBuilder) SetCustomerServiceInfoFromStruct(csInfo CustomerServiceInfo) *EventTicketBuilder {
-	if jsonData, err := json.Marshal(csInfo); err == nil {
-		b.attributes.CSInfo = string(jsonData)
+	jsonData, err := json.Marshal(csInfo)
+	if err != nil {
+		b.errs = append(b.errs, fmt.Errorf("failed to marshal customer service info: %v", err))
+		return b
 	}
+	b.attributes.CSInfo = string(jsonData)
 	return b
 }
 
@@ -332,28 +393,122 @@ func (b *EventTicketBuilder) AddLocalization(language string, localizedAttrs map
 }
 
 // Build returns the final WalletCard structure
+// Errors are ignored; use BuildStrict to have them reported
 func (b *EventTicketBuilder) Build() WalletCard {
-	// Convert TicketAttributes to map for attributes
-	if len(b.walletCard.Card.Data) > 0 {
-		attributesMap := make(WalletCardAttributes)
-
-		// Convert struct to map using JSON marshaling/unmarshaling
-		if jsonData, err := json.Marshal(b.attributes); err == nil {
-			var tempMap map[string]interface{}
-			if err := json.Unmarshal(jsonData, &tempMap); err == nil {
-				// Only include non-zero values
-				for key, value := range tempMap {
-					if value != nil && value != "" && value != int64(0) {
-						attributesMap[key] = value
-					}
-				}
-			}
+	walletCard, _ := b.build()
+	return walletCard
+}
+
+// BuildStrict returns the final WalletCard structure, or an error joining every
+// setter failure, marshal failure and ValidationError found in the card
+// Individual validation errors can be retrieved with errors.As
+func (b *EventTicketBuilder) BuildStrict() (WalletCard, error) {
+	walletCard, err := b.build()
+
+	errs := append([]error{}, b.errs...)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	for _, validationErr := range Validate(walletCard) {
+		errs = append(errs, validationErr)
+	}
+	if b.strictContrast {
+		for _, warning := range CheckContrast(walletCard) {
+			errs = append(errs, warning)
 		}
+	}
+	if len(errs) > 0 {
+		return WalletCard{}, errors.Join(errs...)
+	}
+	return walletCard, nil
+}
+
+// build converts the attributes into the card and returns it
+func (b *EventTicketBuilder) build() (WalletCard, error) {
+	if len(b.walletCard.Card.Data) == 0 {
+		return b.walletCard, nil
+	}
+
+	// Convert TicketAttributes to map for attributes
+	attributesMap := make(WalletCardAttributes)
+
+	attributes := b.attributes
+	applyRelatedCoupons(&attributes, b.relatedCoupons)
+	if b.sanitizeNotices {
+		sanitizeNoticeFields(&attributes)
+	}
 
+	// Convert struct to map using JSON marshaling/unmarshaling
+	jsonData, err := json.Marshal(attributes)
+	if err != nil {
+		b.walletCard.Card.Data[0].Attributes = attributesMap
+		return b.walletCard, fmt.Errorf("failed to marshal ticket attributes: %v", err)
+	}
+	var tempMap map[string]interface{}
+	if err := json.Unmarshal(jsonData, &tempMap); err != nil {
 		b.walletCard.Card.Data[0].Attributes = attributesMap
+		return b.walletCard, fmt.Errorf("failed to convert ticket attributes: %v", err)
+	}
+
+	// Only include non-zero values
+	for key, value := range tempMap {
+		if value != nil && value != "" && value != int64(0) {
+			attributesMap[key] = value
+		}
+	}
+
+	b.walletCard.Card.Data[0].Attributes = attributesMap
+
+	walletCard := b.walletCard
+	if b.sanitizeNotices {
+		// Copy the data so the builder keeps the localizations as given
+		walletCard.Card.Data = append([]WalletCardData{}, walletCard.Card.Data...)
+		walletCard.Card.Data[0].Localization = sanitizeLocalizedNotices(walletCard.Card.Data[0].Localization)
+	}
+	return walletCard, nil
+}
+
+// noticeKeys are the attribute keys of notice fields
+var noticeKeys = []string{
+	"noticeDesc",
+	"relCoupon1.noticeDescription",
+	"relCoupon2.noticeDescription",
+	"relCoupon3.noticeDescription",
+}
+
+// sanitizeLocalizedNotices returns copies of the localizations with their notice fields sanitized
+func sanitizeLocalizedNotices(localizations []WalletCardLocalization) []WalletCardLocalization {
+	if localizations == nil {
+		return nil
 	}
+	sanitized := make([]WalletCardLocalization, len(localizations))
+	for i, localization := range localizations {
+		attributes := make(WalletCardLocalizedAttrs, len(localization.Attributes))
+		for key, value := range localization.Attributes {
+			attributes[key] = value
+		}
+		for _, key := range noticeKeys {
+			if notice, ok := attributes[key].(string); ok && notice != "" {
+				attributes[key] = SanitizeNotice(notice)
+			}
+		}
+		sanitized[i] = WalletCardLocalization{Language: localization.Language, Attributes: attributes}
+	}
+	return sanitized
+}
 
-	return b.walletCard
+// sanitizeNoticeFields sanitizes every notice field of the attributes
+func sanitizeNoticeFields(attributes *TicketAttributes) {
+	for _, notice := range []*string{
+		&attributes.NoticeDesc,
+		&attributes.RelCoupon1NoticeDescription,
+		&attributes.RelCoupon2NoticeDescription,
+		&attributes.RelCoupon3NoticeDescription,
+	} {
+		if *notice != "" {
+			*notice = SanitizeNotice(*notice)
+		}
+	}
 }
 
 // BuildAsJSON returns the wallet card as JSON string
@@ -2,28 +2,43 @@ package wallet
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"time"
+
+	"github.com/google/uuid"
 )
 
 const (
-	// API paths
-	pathATWLink    = "/v1/wallet/atw/link"
-	pathUpdateCard = "/v1/wallet/card/update"
-	pathCancelCard = "/v1/wallet/card/cancel"
-	pathGetCard    = "/v1/wallet/card/get"
-	pathCardState  = "/v1/wallet/card/state"
+	// Legacy get card path, only served by wallettest
+	pathGetCard = "/v1/wallet/card/get"
+
+	// Samsung server API paths, formatted with the cc2 country code and card ID
+	pathUpdateNotification = "/%s/wltex/cards/%s/updates"
+	pathCancelNotification = "/%s/wltex/cards/%s/cancels"
+	pathAddCard            = "/%s/atw/v1/cards/%s"
+
+	// Samsung server API headers
+	headerPartnerID   = "x-smcs-partner-id"
+	headerRequestID   = "x-request-id"
+	headerCountryCode = "x-smcs-cc2"
+
+	// defaultHTTPTimeout is used when Config.Timeout is not set
+	defaultHTTPTimeout = 30 * time.Second
 )
 
 // Client represents the Samsung Wallet client
 type Client struct {
-	config     *Config
-	httpClient *http.Client
-	jwtManager *JWTManager
-	baseURL    string
+	config           *Config
+	httpClient       *http.Client
+	jwtManager       *JWTManager
+	endpointResolver EndpointResolver
+	retryPolicy      *RetryPolicy
+	linkConfig       linkConfig
 }
 
 // NewClient creates a new Samsung Wallet client
@@ -48,6 +63,12 @@ func NewClient(config *Config) (*Client, error) {
 		return nil, fmt.Errorf("certificate ID is required")
 	}
 
+	switch config.Environment {
+	case "", EnvironmentSandbox, EnvironmentProduction:
+	default:
+		return nil, fmt.Errorf("unsupported environment: %s", config.Environment)
+	}
+
 	// Initialize JWT manager with Samsung public key and partner private key
 	jwtManager, err := NewJWTManager(
 		config.PartnerPrivateKey,
@@ -59,170 +80,125 @@ func NewClient(config *Config) (*Client, error) {
 		return nil, fmt.Errorf("failed to create JWT manager: %v", err)
 	}
 
-	baseURL := config.BaseURL
-	if baseURL == "" {
-		baseURL = "https://a.swallet.link" // Samsung Wallet ATW URL
+	timeout := config.Timeout
+	if timeout == 0 {
+		timeout = defaultHTTPTimeout
 	}
 
 	return &Client{
-		config:     config,
-		jwtManager: jwtManager,
-		httpClient: &http.Client{Timeout: 30 * time.Second},
-		baseURL:    baseURL,
+		config:           config,
+		jwtManager:       jwtManager,
+		httpClient:       &http.Client{Timeout: timeout},
+		endpointResolver: defaultResolver(config),
+		retryPolicy:      DefaultRetryPolicy(),
+		linkConfig:       newLinkConfig(config),
 	}, nil
 }
 
 // CreateATWLink creates an Add to Samsung Wallet link with legacy CardData
+//
+// Deprecated: Use CreateLink with a WalletCard and LinkOption values.
 func (c *Client) CreateATWLink(cardID string, cardData CardData, linkType string, callbackURL ...string) (string, error) {
-	if cardID == "" {
-		return "", fmt.Errorf("card ID is required (obtain from Partners Portal when registering card type)")
-	}
-
-	if linkType == "" {
-		linkType = "data_transmit" // default to data transmit
-	}
-
-	var callback string
-	if len(callbackURL) > 0 {
-		callback = callbackURL[0]
-	}
-
-	switch linkType {
-	case "data_transmit":
-		return c.createDataTransmitLink(cardID, cardData, callback)
-	case "data_fetch":
-		return c.createDataFetchLink(cardID, cardData, callback)
-	default:
-		return "", fmt.Errorf("unsupported link type: %s", linkType)
+	link, err := c.createLink(cardID, cardData, cardData.CardID, legacyLinkOptions(linkType, callbackURL))
+	if err != nil {
+		return "", err
 	}
+	return link.URL, nil
 }
 
 // CreateATWLinkFromWalletCard creates an Add to Samsung Wallet link with official WalletCard structure
+//
+// Deprecated: Use CreateLink, which takes typed LinkOption values and returns the link's details.
 func (c *Client) CreateATWLinkFromWalletCard(cardID string, walletCard WalletCard, linkType string, callbackURL ...string) (string, error) {
-	if cardID == "" {
-		return "", fmt.Errorf("card ID is required (obtain from Partners Portal when registering card type)")
-	}
-
-	if linkType == "" {
-		linkType = "data_transmit" // default to data transmit
-	}
-
-	var callback string
-	if len(callbackURL) > 0 {
-		callback = callbackURL[0]
-	}
-
-	switch linkType {
-	case "data_transmit":
-		return c.createDataTransmitLinkFromWalletCard(cardID, walletCard, callback)
-	case "data_fetch":
-		return c.createDataFetchLinkFromWalletCard(cardID, walletCard, callback)
-	default:
-		return "", fmt.Errorf("unsupported link type: %s", linkType)
-	}
-}
-
-// createDataTransmitLink creates a data transmit link
-func (c *Client) createDataTransmitLink(cardID string, cardData CardData, callbackURL string) (string, error) {
-	// Create CDATA token according to Samsung specification
-	// This generates a JWT with Samsung-specific headers and 30-second expiration
-	cdata, err := c.jwtManager.CreateDataTransmitToken(cardData)
-	if err != nil {
-		return "", fmt.Errorf("failed to create CDATA token: %v", err)
-	}
-
-	// Build ATW link according to Samsung Wallet API Guidelines
-	// URL format: https://a.swallet.link/atw/v3/{cardId}#Clip?cdata={cdata}
-	// cardId is the fixed identifier from Partners Portal (not individual card instance ID)
-	atwURL := fmt.Sprintf("https://a.swallet.link/atw/v3/%s#Clip?cdata=%s", cardID, cdata)
-
-	return atwURL, nil
-}
-
-// createDataFetchLink creates a data fetch link
-func (c *Client) createDataFetchLink(cardID string, cardData CardData, callbackURL string) (string, error) {
-	// Data Fetch Link format: https://a.swallet.link/atw/v3/{certificateId}/{cardId}#Clip?pdata={pdata}
-	// certificateId and cardId are fixed identifiers from Partners Portal
-	// pdata is unique reference ID for this specific card instance
-
-	// Generate a unique reference ID for this card instance
-	// In a real implementation, this should be a secure, non-predictable ID
-	refId := fmt.Sprintf("ref_%s_%d", cardData.CardID, time.Now().Unix())
-
-	// Both certificateId and cardId must be obtained from Partners Portal
-	if c.config.CertificateID == "" {
-		return "", fmt.Errorf("certificate ID is required for data fetch links")
-	}
-
-	atwURL := fmt.Sprintf("https://a.swallet.link/atw/v3/%s/%s#Clip?pdata=%s",
-		c.config.CertificateID, cardID, refId)
-
-	return atwURL, nil
-}
-
-// createDataTransmitLinkFromWalletCard creates a data transmit link from WalletCard
-func (c *Client) createDataTransmitLinkFromWalletCard(cardID string, walletCard WalletCard, callbackURL string) (string, error) {
-	// Create CDATA token according to Samsung specification
-	cdata, err := c.jwtManager.CreateDataTransmitTokenFromWalletCard(walletCard)
+	link, err := c.CreateLink(cardID, walletCard, legacyLinkOptions(linkType, callbackURL)...)
 	if err != nil {
-		return "", fmt.Errorf("failed to create CDATA token: %v", err)
+		return "", err
 	}
-
-	// Build ATW link according to Samsung Wallet API Guidelines
-	atwURL := fmt.Sprintf("https://a.swallet.link/atw/v3/%s#Clip?cdata=%s", cardID, cdata)
-
-	return atwURL, nil
+	return link.URL, nil
 }
 
-// createDataFetchLinkFromWalletCard creates a data fetch link from WalletCard
-func (c *Client) createDataFetchLinkFromWalletCard(cardID string, walletCard WalletCard, callbackURL string) (string, error) {
-	// Generate a unique reference ID for this card instance
-	refId := fmt.Sprintf("ref_%d", time.Now().Unix())
-
-	if c.config.CertificateID == "" {
-		return "", fmt.Errorf("certificate ID is required for data fetch links")
+// legacyLinkOptions converts the positional link arguments of the deprecated link functions
+func legacyLinkOptions(linkType string, callbackURL []string) []LinkOption {
+	opts := []LinkOption{WithLinkType(LinkType(linkType))}
+	if len(callbackURL) > 0 && callbackURL[0] != "" {
+		opts = append(opts, WithCallbackURL(callbackURL[0]))
 	}
-
-	atwURL := fmt.Sprintf("https://a.swallet.link/atw/v3/%s/%s#Clip?pdata=%s",
-		c.config.CertificateID, cardID, refId)
-
-	return atwURL, nil
+	return opts
 }
 
 // UpdateCard updates a wallet card
+//
+// Deprecated: Use SendUpdateNotification, which targets the card by refId.
+// See UpdateCardWithContext for what is sent.
 func (c *Client) UpdateCard(cardID string, cardData CardData, countryCode string) error {
-	request := map[string]interface{}{
-		"partner_id":   c.config.PartnerID,
-		"card_id":      cardID,
-		"card_data":    cardData,
-		"country_code": countryCode,
-	}
+	return c.UpdateCardWithContext(context.Background(), cardID, cardData, countryCode)
+}
 
-	_, err := c.makeAPIRequest("POST", fmt.Sprintf("%s/%s", pathUpdateCard, countryCode), request)
+// UpdateCardWithContext updates a wallet card, honoring the context's cancellation and deadline
+//
+// Deprecated: Use SendUpdateNotification, which targets the card by refId. This method
+// now sends an update notification for the refId cardData.CardID, and Samsung Wallet
+// re-fetches the card through your Get Card Data endpoint. The other cardData fields are
+// no longer sent, since Samsung has no API taking the legacy CardData. An empty
+// cardData.CardType is sent as an event ticket, the only legacy card type.
+func (c *Client) UpdateCardWithContext(ctx context.Context, cardID string, cardData CardData, countryCode string) error {
+	cardType := cardData.CardType
+	if cardType == "" {
+		cardType = CardTypeEventTicket
+	}
+	_, err := c.SendUpdateNotification(ctx, &UpdateNotificationRequest{
+		CardID:      cardID,
+		CountryCode: countryCode,
+		RefID:       cardData.CardID,
+		CardType:    cardType.samsungType(),
+		State:       CardStateUpdated,
+	})
 	return err
 }
 
 // CancelCard cancels wallet cards for a specific event
+//
+// Deprecated: Use SendCancelNotification, which needs the card ID and country code
+// Samsung requires and reports the affected cards. See CancelCardWithContext.
 func (c *Client) CancelCard(eventID string, reason string) error {
-	request := CancelCardRequest{
-		PartnerID: c.config.PartnerID,
-		EventID:   eventID,
-		Reason:    reason,
-	}
+	return c.CancelCardWithContext(context.Background(), eventID, reason)
+}
 
-	_, err := c.makeAPIRequest("POST", pathCancelCard, request)
-	return err
+// CancelCardWithContext cancels wallet cards for a specific event, honoring the context's cancellation and deadline
+//
+// Deprecated: Use SendCancelNotification. Samsung routes cancel notifications by card ID
+// and country code, which this method doesn't take, so it sends nothing and returns an
+// error wrapping errors.ErrUnsupported.
+func (c *Client) CancelCardWithContext(ctx context.Context, eventID string, reason string) error {
+	return fmt.Errorf("%w: CancelCard can't address a Samsung card, use SendCancelNotification with the card ID and country code to cancel event %s", errors.ErrUnsupported, eventID)
 }
 
 // GetCardData retrieves card data
+//
+// Deprecated: Samsung has no API for reading card data back, it fetches cards from your
+// Get Card Data endpoint. See GetCardDataWithContext.
 func (c *Client) GetCardData(cardID string, countryCode string) (*CardData, error) {
+	return c.GetCardDataWithContext(context.Background(), cardID, countryCode)
+}
+
+// GetCardDataWithContext retrieves card data, honoring the context's cancellation and deadline
+//
+// Deprecated: Samsung has no API for reading card data back. The legacy get card path is
+// only served by wallettest, so this method only works against Config.APIBaseURL test servers.
+func (c *Client) GetCardDataWithContext(ctx context.Context, cardID string, countryCode string) (*CardData, error) {
 	request := map[string]interface{}{
 		"partner_id":   c.config.PartnerID,
 		"card_id":      cardID,
 		"country_code": countryCode,
 	}
 
-	response, err := c.makeAPIRequest("POST", fmt.Sprintf("%s/%s", pathGetCard, countryCode), request)
+	response, err := c.makeAPIRequest(ctx, apiRequest{
+		method:      "POST",
+		path:        fmt.Sprintf("%s/%s", pathGetCard, countryCode),
+		countryCode: countryCode,
+		payload:     request,
+		idempotent:  true, // Read-only
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -250,50 +226,196 @@ func (c *Client) HandleCallback(callbackData []byte) (*CardStateCallback, error)
 	return &callback, nil
 }
 
+// apiRequest describes a single Samsung Wallet server API call
+type apiRequest struct {
+	method      string
+	path        string
+	countryCode string // cc2 country code, validated by the endpoint resolver
+	refID       string // Optional refId bound into the authorization token
+	payload     interface{}
+	idempotent  bool // Safe to repeat even if Samsung may already have processed it
+}
+
+// apiResponse holds the outcome of one attempt of an API call
+type apiResponse struct {
+	statusCode int
+	header     http.Header
+	body       []byte
+	err        error // Transport error, if the request never produced a response
+}
+
 // makeAPIRequest makes an HTTP request to Samsung Wallet API
-func (c *Client) makeAPIRequest(method, path string, payload interface{}) ([]byte, error) {
-	var body io.Reader
-	if payload != nil {
-		jsonData, err := json.Marshal(payload)
+// The request is bound to ctx, so cancelling ctx aborts the call in flight,
+// and failed attempts are retried according to the client's retry policy
+func (c *Client) makeAPIRequest(ctx context.Context, apiReq apiRequest) ([]byte, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	baseURL, err := c.endpointResolver.ResolveEndpoint(c.config.Environment, apiReq.countryCode)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve API endpoint: %v", err)
+	}
+
+	var jsonData []byte
+	if apiReq.payload != nil {
+		jsonData, err = json.Marshal(apiReq.payload)
 		if err != nil {
 			return nil, fmt.Errorf("failed to marshal request payload: %v", err)
 		}
-		body = bytes.NewBuffer(jsonData)
 	}
 
-	req, err := http.NewRequest(method, c.baseURL+path, body)
+	requestURL := baseURL + apiReq.path
+	maxAttempts := c.retryPolicy.maxAttempts()
+
+	// The same request ID is sent on every attempt so Samsung can correlate retries
+	requestID := uuid.New().String()
+
+	for attempt := 1; ; attempt++ {
+		resp := c.sendAPIRequest(ctx, apiReq, requestURL, requestID, jsonData)
+		if resp.err == nil && resp.statusCode < 400 {
+			return resp.body, nil
+		}
+
+		// Surface the context error directly so callers can match context.Canceled / DeadlineExceeded
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+
+		var retryable bool
+		if resp.err != nil {
+			retryable = isRetryableTransportError(resp.err, apiReq.idempotent)
+		} else {
+			retryable = isRetryableStatus(resp.statusCode, apiReq.idempotent)
+		}
+
+		var delay time.Duration
+		willRetry := retryable && attempt < maxAttempts
+		if willRetry {
+			delay = c.retryPolicy.backoff(attempt)
+			if retryAfter, ok := parseRetryAfter(resp.header.Get("Retry-After"), time.Now()); ok && retryAfter > delay {
+				delay = retryAfter
+				// Give up rather than block for longer than MaxBackoff
+				if retryAfter > c.retryPolicy.maxDelay() {
+					willRetry = false
+					delay = 0
+				}
+			}
+			// Give up early rather than sleep past the caller's deadline
+			if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+				willRetry = false
+				delay = 0
+			}
+		}
+
+		err := resp.toError(requestID)
+		if c.retryPolicy != nil && c.retryPolicy.OnAttempt != nil {
+			c.retryPolicy.OnAttempt(RetryAttempt{
+				Attempt:    attempt,
+				Method:     apiReq.method,
+				URL:        requestURL,
+				StatusCode: resp.statusCode,
+				Err:        err,
+				WillRetry:  willRetry,
+				Delay:      delay,
+			})
+		}
+
+		if !willRetry {
+			return nil, err
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// sendAPIRequest performs a single attempt of an API call
+func (c *Client) sendAPIRequest(ctx context.Context, apiReq apiRequest, requestURL, requestID string, jsonData []byte) apiResponse {
+	var body io.Reader
+	if jsonData != nil {
+		body = bytes.NewReader(jsonData)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, apiReq.method, requestURL, body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create HTTP request: %v", err)
+		return apiResponse{err: fmt.Errorf("failed to create HTTP request: %v", err)}
 	}
 
 	// Set headers
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Accept", "application/json")
 
-	// Add authentication header if needed
-	// This would typically be a JWT token or API key
-	// Implementation depends on Samsung Wallet's authentication requirements
+	req.Header.Set(headerPartnerID, c.config.PartnerID)
+	req.Header.Set(headerRequestID, requestID)
+	if apiReq.countryCode != "" {
+		req.Header.Set(headerCountryCode, apiReq.countryCode)
+	}
+
+	// A fresh authorization token per attempt keeps its utc header current
+	token, err := c.jwtManager.CreateAuthToken(apiReq.method, apiReq.path, apiReq.refID)
+	if err != nil {
+		return apiResponse{err: fmt.Errorf("failed to create authorization token: %v", err)}
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to make HTTP request: %v", err)
+		return apiResponse{err: err}
 	}
 	defer resp.Body.Close()
 
 	responseBody, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %v", err)
+		return apiResponse{err: fmt.Errorf("failed to read response body: %v", err)}
 	}
 
-	if resp.StatusCode >= 400 {
-		var apiError APIError
-		if err := json.Unmarshal(responseBody, &apiError); err != nil {
-			return nil, fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(responseBody))
-		}
-		return nil, &apiError
+	return apiResponse{
+		statusCode: resp.StatusCode,
+		header:     resp.Header,
+		body:       responseBody,
 	}
+}
+
+// toError converts a failed attempt into the error returned to callers
+func (r apiResponse) toError(requestID string) error {
+	if r.err != nil {
+		return fmt.Errorf("failed to make HTTP request: %w", r.err)
+	}
+
+	// Prefer the request ID echoed by Samsung, falling back to the one we sent
+	if echoed := r.header.Get(headerRequestID); echoed != "" {
+		requestID = echoed
+	}
+	return newAPIError(r.statusCode, requestID, r.body)
+}
+
+// SetEndpointResolver sets how server API base URLs are resolved per country code
+// A nil resolver restores the default from Config
+func (c *Client) SetEndpointResolver(resolver EndpointResolver) {
+	if resolver == nil {
+		resolver = defaultResolver(c.config)
+	}
+	c.endpointResolver = resolver
+}
+
+// defaultResolver returns the resolver for config; an explicit API base URL replaces
+// the default host
+func defaultResolver(config *Config) EndpointResolver {
+	if apiBaseURL := firstNonEmpty(config.APIBaseURL, config.BaseURL); apiBaseURL != "" {
+		return StaticEndpoint(apiBaseURL)
+	}
+	return DefaultEndpointResolver()
+}
 
-	return responseBody, nil
+// SetRetryPolicy sets the retry policy for server API calls (nil disables retries)
+func (c *Client) SetRetryPolicy(policy *RetryPolicy) {
+	c.retryPolicy = policy
 }
 
 // SetHTTPClient sets a custom HTTP client
@@ -0,0 +1,77 @@
+package wallet_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/abyssparanoia/samsung-wallet-go/wallet"
+	"github.com/abyssparanoia/samsung-wallet-go/wallet/wallettest"
+)
+
+func TestContextVariantsHonorCancellation(t *testing.T) {
+	server := wallettest.NewServer(t)
+	client := server.Client(t)
+	server.SetCardData("card-1", wallet.CardData{CardID: "card-1"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := client.GetCardDataWithContext(ctx, "card-1", "KR"); !errors.Is(err, context.Canceled) {
+		t.Errorf("GetCardDataWithContext() error = %v, want context.Canceled", err)
+	}
+	if err := client.UpdateCardWithContext(ctx, "card", wallet.CardData{CardID: "ref-1"}, "KR"); !errors.Is(err, context.Canceled) {
+		t.Errorf("UpdateCardWithContext() error = %v, want context.Canceled", err)
+	}
+	if calls := server.Calls(); len(calls) != 0 {
+		t.Errorf("calls = %+v, want none after cancellation", calls)
+	}
+}
+
+func TestCancelCardIsUnsupported(t *testing.T) {
+	server := wallettest.NewServer(t)
+	client := server.Client(t)
+
+	if err := client.CancelCardWithContext(context.Background(), "EVENT001", "postponed"); !errors.Is(err, errors.ErrUnsupported) {
+		t.Errorf("CancelCardWithContext() error = %v, want errors.ErrUnsupported", err)
+	}
+	if err := client.CancelCard("EVENT001", "postponed"); !errors.Is(err, errors.ErrUnsupported) {
+		t.Errorf("CancelCard() error = %v, want errors.ErrUnsupported", err)
+	}
+	if calls := server.Calls(); len(calls) != 0 {
+		t.Errorf("calls = %+v, want none", calls)
+	}
+}
+
+func TestNonIdempotentCallsAreNotRetried(t *testing.T) {
+	server := wallettest.NewServer(t)
+	client := server.Client(t)
+	card := wallet.WalletCard{Card: wallet.WalletCardBody{
+		Type: "ticket",
+		Data: []wallet.WalletCardData{{RefID: "ref-1", Language: "en", Attributes: wallet.WalletCardAttributes{"title": "Concert"}}},
+	}}
+
+	// Samsung may have added the card before failing, so AddCard must not repeat it
+	server.FailNext(wallettest.CallAddCard, http.StatusInternalServerError, "500", "internal error")
+	_, err := client.AddCard(context.Background(), &wallet.AddCardRequest{
+		CardID: "card", CountryCode: "KR", UserID: "user-1", Card: card,
+	})
+	if !errors.Is(err, wallet.ErrServerError) {
+		t.Errorf("AddCard() error = %v, want ErrServerError", err)
+	}
+	if calls := server.CallsOf(wallettest.CallAddCard); len(calls) != 1 {
+		t.Errorf("add card calls = %d, want 1", len(calls))
+	}
+
+	// Cancel notifications are idempotent and retried on the same error
+	server.FailNext(wallettest.CallCancel, http.StatusInternalServerError, "500", "internal error")
+	if _, err := client.SendCancelNotification(context.Background(), &wallet.CancelNotificationRequest{
+		CardID: "card", CountryCode: "KR", EventID: "EVENT001",
+	}); err != nil {
+		t.Errorf("SendCancelNotification() error = %v", err)
+	}
+	if calls := server.CallsOf(wallettest.CallCancel); len(calls) != 2 {
+		t.Errorf("cancel calls = %d, want 2", len(calls))
+	}
+}
@@ -0,0 +1,126 @@
+package wallet
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Color is an sRGB card color
+type Color struct {
+	R, G, B uint8
+}
+
+// Common colors
+var (
+	ColorWhite = Color{0xFF, 0xFF, 0xFF}
+	ColorBlack = Color{0x00, 0x00, 0x00}
+)
+
+// ParseColor parses a hex color, "#RRGGBB" or the short form "#RGB"
+func ParseColor(s string) (Color, error) {
+	hex, ok := strings.CutPrefix(s, "#")
+	if !ok {
+		return Color{}, fmt.Errorf("invalid color %q: must start with #", s)
+	}
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	if len(hex) != 6 {
+		return Color{}, fmt.Errorf("invalid color %q: must be #RRGGBB or #RGB", s)
+	}
+	n, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return Color{}, fmt.Errorf("invalid color %q: must be hexadecimal", s)
+	}
+	return Color{uint8(n >> 16), uint8(n >> 8), uint8(n)}, nil
+}
+
+// normalizeColor returns a hex color in #RRGGBB form; other values are returned unchanged
+func normalizeColor(s string) string {
+	c, err := ParseColor(s)
+	if err != nil {
+		return s
+	}
+	return c.String()
+}
+
+// MustParseColor is like ParseColor but panics on error, for colors known at compile time
+func MustParseColor(s string) Color {
+	c, err := ParseColor(s)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// String returns the color in the #RRGGBB form Samsung Wallet accepts
+func (c Color) String() string {
+	return fmt.Sprintf("#%02X%02X%02X", c.R, c.G, c.B)
+}
+
+// Luminance returns the WCAG relative luminance of the color, from 0 (black) to 1 (white)
+func (c Color) Luminance() float64 {
+	linear := func(v uint8) float64 {
+		s := float64(v) / 255
+		if s <= 0.04045 {
+			return s / 12.92
+		}
+		return math.Pow((s+0.055)/1.055, 2.4)
+	}
+	return 0.2126*linear(c.R) + 0.7152*linear(c.G) + 0.0722*linear(c.B)
+}
+
+// ContrastRatio returns the WCAG contrast ratio of two colors, from 1 to 21
+func ContrastRatio(a, b Color) float64 {
+	la, lb := a.Luminance(), b.Luminance()
+	if la < lb {
+		la, lb = lb, la
+	}
+	return (la + 0.05) / (lb + 0.05)
+}
+
+// Minimum WCAG contrast ratios
+const (
+	ContrastAA      = 4.5 // AA for normal text
+	ContrastAALarge = 3.0 // AA for large text
+	ContrastAAA     = 7.0 // AAA for normal text
+)
+
+// FontColor is a fontColor attribute value: light, dark or a #RRGGBB color
+type FontColor string
+
+const (
+	FontColorLight FontColor = "light" // Light text, for dark backgrounds
+	FontColorDark  FontColor = "dark"  // Dark text, for light backgrounds
+)
+
+// FontColorOf returns a hex font color
+func FontColorOf(c Color) FontColor {
+	return FontColor(c.String())
+}
+
+// FontColorFor returns the light or dark font color that contrasts more with background
+func FontColorFor(background Color) FontColor {
+	if ContrastRatio(background, ColorWhite) >= ContrastRatio(background, ColorBlack) {
+		return FontColorLight
+	}
+	return FontColorDark
+}
+
+// Color returns the color the font is drawn in, approximating light and dark as white and black
+func (f FontColor) Color() (Color, error) {
+	switch f {
+	case FontColorLight:
+		return ColorWhite, nil
+	case FontColorDark:
+		return ColorBlack, nil
+	default:
+		c, err := ParseColor(string(f))
+		if err != nil {
+			return Color{}, fmt.Errorf("invalid font color %q: must be light, dark or a hex color", f)
+		}
+		return c, nil
+	}
+}
@@ -0,0 +1,99 @@
+package wallet
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParseColor(t *testing.T) {
+	tests := map[string]Color{
+		"#1F2937": {0x1F, 0x29, 0x37},
+		"#fde047": {0xFD, 0xE0, 0x47},
+		"#FFF":    ColorWhite,
+		"#a1c":    {0xAA, 0x11, 0xCC},
+	}
+	for s, want := range tests {
+		if got, err := ParseColor(s); err != nil || got != want {
+			t.Errorf("ParseColor(%q) = %v, %v, want %v", s, got, err, want)
+		}
+	}
+	for _, s := range []string{"", "FFFFFF", "#FFFF", "#GGGGGG", "#1F29370", "#+FFFFF"} {
+		if _, err := ParseColor(s); err == nil {
+			t.Errorf("ParseColor(%q) succeeded", s)
+		}
+	}
+}
+
+func TestContrastRatio(t *testing.T) {
+	tests := []struct {
+		a, b Color
+		want float64
+	}{
+		{ColorWhite, ColorBlack, 21},
+		{ColorBlack, ColorWhite, 21},
+		{ColorWhite, ColorWhite, 1},
+		{MustParseColor("#777777"), ColorWhite, 4.48},
+		{MustParseColor("#1F2937"), ColorWhite, 14.68},
+	}
+	for _, tt := range tests {
+		if got := ContrastRatio(tt.a, tt.b); math.Abs(got-tt.want) > 0.005 {
+			t.Errorf("ContrastRatio(%s, %s) = %.3f, want %.2f", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestFontColorFor(t *testing.T) {
+	tests := map[string]FontColor{
+		"#000000": FontColorLight,
+		"#1F2937": FontColorLight,
+		"#FFFFFF": FontColorDark,
+		"#FDE047": FontColorDark,
+	}
+	for background, want := range tests {
+		if got := FontColorFor(MustParseColor(background)); got != want {
+			t.Errorf("FontColorFor(%s) = %s, want %s", background, got, want)
+		}
+	}
+}
+
+func TestShortColorsPassBuildStrict(t *testing.T) {
+	card, err := newTestTicket().SetStyling("#000", "#FFF", "#f00").BuildStrict()
+	if err != nil {
+		t.Fatalf("BuildStrict() error = %v", err)
+	}
+	attributes := card.Card.Data[0].Attributes
+	if attributes["bgColor"] != "#000000" || attributes["fontColor"] != "#FFFFFF" || attributes["blinkColor"] != "#FF0000" {
+		t.Errorf("attributes = %v, want colors in #RRGGBB form", attributes)
+	}
+
+	themes, err := NewThemeRegistry(Theme{Name: "short", Background: MustParseColor("#000"), Font: FontColor("#FFF")})
+	if err != nil {
+		t.Fatalf("NewThemeRegistry() error = %v", err)
+	}
+	theme, _ := themes.Lookup("short")
+	if _, err := newTestTicket().SetTheme(theme).BuildStrict(); err != nil {
+		t.Errorf("BuildStrict() with theme error = %v", err)
+	}
+}
+
+func TestStrictContrast(t *testing.T) {
+	theme := Theme{Name: "grey", Background: MustParseColor("#777777"), Font: FontColorLight}
+	if warnings := theme.Warnings(); len(warnings) != 1 {
+		t.Errorf("Warnings() = %v, want one warning", warnings)
+	}
+
+	card, err := newTestTicket().SetTheme(theme).BuildStrict()
+	if err != nil {
+		t.Fatalf("BuildStrict() error = %v", err)
+	}
+	if warnings := CheckContrast(card); len(warnings) != 1 || warnings[0].Field != "card.data[0].attributes.fontColor" {
+		t.Errorf("CheckContrast() = %v, want one fontColor warning", warnings)
+	}
+
+	if _, err := newTestTicket().SetTheme(theme).SetStrictContrast(true).BuildStrict(); err == nil {
+		t.Error("BuildStrict() with strict contrast succeeded")
+	}
+	if _, err := newTestTicket().SetTheme(Theme{Name: "auto", Background: MustParseColor("#777777")}).SetStrictContrast(true).BuildStrict(); err != nil {
+		t.Errorf("BuildStrict() with a derived font color error = %v", err)
+	}
+}
@@ -0,0 +1,137 @@
+package wallet
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// utcOffsetSuffix is appended to a date attribute key to name its UTC offset attribute
+const utcOffsetSuffix = ".utcOffset"
+
+// utcOffsetPattern matches UTC offset attribute values, e.g. "UTC+09:00"
+var utcOffsetPattern = regexp.MustCompile(`^UTC([+-])(\d{2}):(\d{2})$`)
+
+// Range of UTC offsets in use, UTC-12:00 to UTC+14:00
+const (
+	minUTCOffset = -12 * 3600
+	maxUTCOffset = 14 * 3600
+)
+
+// FormatUTCOffset returns the UTC offset of t in the Samsung form, e.g. "UTC+09:00"
+// Offsets are rounded down to whole minutes
+func FormatUTCOffset(t time.Time) string {
+	_, offset := t.Zone()
+	sign := '+'
+	if offset < 0 {
+		sign, offset = '-', -offset
+	}
+	return fmt.Sprintf("UTC%c%02d:%02d", sign, offset/3600, offset%3600/60)
+}
+
+// ParseUTCOffset parses a UTC offset attribute value into a fixed zone named after it
+// Offsets must be between UTC-12:00 and UTC+14:00
+func ParseUTCOffset(s string) (*time.Location, error) {
+	m := utcOffsetPattern.FindStringSubmatch(s)
+	if m == nil {
+		return nil, fmt.Errorf("invalid UTC offset: %q", s)
+	}
+	hours, _ := strconv.Atoi(m[2])
+	minutes, _ := strconv.Atoi(m[3])
+	offset := hours*3600 + minutes*60
+	if m[1] == "-" {
+		offset = -offset
+	}
+	if minutes > 59 || offset < minUTCOffset || offset > maxUTCOffset {
+		return nil, fmt.Errorf("invalid UTC offset: %q: must be between UTC-12:00 and UTC+14:00", s)
+	}
+	return time.FixedZone(s, offset), nil
+}
+
+// dateTime restores a time from epoch milliseconds and its optional UTC offset attribute
+// Without an offset the time is returned in UTC
+func dateTime(ms int64, utcOffset string) (time.Time, error) {
+	t := time.UnixMilli(ms).UTC()
+	if utcOffset == "" {
+		return t, nil
+	}
+	location, err := ParseUTCOffset(utcOffset)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return t.In(location), nil
+}
+
+// ParseDateAttribute reads the date attribute key from card attributes in the zone of
+// its key.utcOffset attribute, e.g. startDate and startDate.utcOffset
+// It reports false if the attribute is not set
+func ParseDateAttribute(attributes map[string]interface{}, key string) (time.Time, bool, error) {
+	ms, ok := epochMillis(attributes[key])
+	if !ok || ms == 0 {
+		return time.Time{}, false, nil
+	}
+	utcOffset, _ := attributes[key+utcOffsetSuffix].(string)
+	t, err := dateTime(ms, utcOffset)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("failed to parse %s: %v", key+utcOffsetSuffix, err)
+	}
+	return t, true, nil
+}
+
+// IssueTime returns issueDate in the zone of issueDate.utcOffset; zero if not set
+func (a TicketAttributes) IssueTime() (time.Time, error) {
+	return a.localTime(a.IssueDate, a.IssueDateUTCOffset)
+}
+
+// StartTime returns startDate in the zone of startDate.utcOffset; zero if not set
+func (a TicketAttributes) StartTime() (time.Time, error) {
+	return a.localTime(a.StartDate, a.StartDateUTCOffset)
+}
+
+// EndTime returns endDate in the zone of endDate.utcOffset; zero if not set
+func (a TicketAttributes) EndTime() (time.Time, error) {
+	return a.localTime(a.EndDate, a.EndDateUTCOffset)
+}
+
+// RelatedCouponNotificationTime returns relCouponN.notificationTime in the zone of
+// relCouponN.notificationTime.utcOffset for coupon n (1-3); zero if not set
+func (a TicketAttributes) RelatedCouponNotificationTime(n int) (time.Time, error) {
+	switch n {
+	case 1:
+		return a.localTime(a.RelCoupon1NotificationTime, a.RelCoupon1NotificationTimeUTCOffset)
+	case 2:
+		return a.localTime(a.RelCoupon2NotificationTime, a.RelCoupon2NotificationTimeUTCOffset)
+	case 3:
+		return a.localTime(a.RelCoupon3NotificationTime, a.RelCoupon3NotificationTimeUTCOffset)
+	default:
+		return time.Time{}, fmt.Errorf("related coupon must be 1-%d, got %d", MaxRelatedCoupons, n)
+	}
+}
+
+// localTime restores a date attribute, leaving unset dates zero
+func (a TicketAttributes) localTime(ms int64, utcOffset string) (time.Time, error) {
+	if ms == 0 {
+		return time.Time{}, nil
+	}
+	return dateTime(ms, utcOffset)
+}
+
+// SetIssueDate sets the issue date and its UTC offset from t's location
+func (b *EventTicketBuilder) SetIssueDate(t time.Time) *EventTicketBuilder {
+	b.attributes.IssueDate, b.attributes.IssueDateUTCOffset = t.UnixMilli(), FormatUTCOffset(t)
+	return b
+}
+
+// SetStartDate sets the event start date and its UTC offset from t's location
+// Use the venue's location so the time displays as venue-local for travellers
+func (b *EventTicketBuilder) SetStartDate(t time.Time) *EventTicketBuilder {
+	b.attributes.StartDate, b.attributes.StartDateUTCOffset = t.UnixMilli(), FormatUTCOffset(t)
+	return b
+}
+
+// SetEndDate sets the event end date and its UTC offset from t's location
+func (b *EventTicketBuilder) SetEndDate(t time.Time) *EventTicketBuilder {
+	b.attributes.EndDate, b.attributes.EndDateUTCOffset = t.UnixMilli(), FormatUTCOffset(t)
+	return b
+}
@@ -0,0 +1,116 @@
+package wallet
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseUTCOffset(t *testing.T) {
+	valid := map[string]int{
+		"UTC+00:00": 0,
+		"UTC+09:00": 9 * 3600,
+		"UTC+05:45": 5*3600 + 45*60,
+		"UTC-03:30": -(3*3600 + 30*60),
+		"UTC+14:00": 14 * 3600,
+		"UTC-12:00": -12 * 3600,
+	}
+	for s, want := range valid {
+		location, err := ParseUTCOffset(s)
+		if err != nil {
+			t.Errorf("ParseUTCOffset(%q) error = %v", s, err)
+			continue
+		}
+		if _, offset := time.Unix(0, 0).In(location).Zone(); offset != want {
+			t.Errorf("ParseUTCOffset(%q) offset = %d, want %d", s, offset, want)
+		}
+	}
+
+	for _, s := range []string{"", "UTC+9", "UTC+09:60", "UTC+14:59", "UTC+14:01", "UTC-12:30", "UTC-13:00", "GMT+09:00", "UTC 09:00", "+09:00"} {
+		if _, err := ParseUTCOffset(s); err == nil {
+			t.Errorf("ParseUTCOffset(%q) succeeded", s)
+		}
+	}
+}
+
+func TestFormatUTCOffset(t *testing.T) {
+	tests := map[string]string{
+		"Asia/Seoul":          "UTC+09:00",
+		"Asia/Kathmandu":      "UTC+05:45",
+		"America/St_Johns":    "UTC-02:30", // Daylight saving time in July
+		"Pacific/Kiritimati":  "UTC+14:00",
+		"UTC":                 "UTC+00:00",
+		"America/Los_Angeles": "UTC-07:00",
+	}
+	for name, want := range tests {
+		location, err := time.LoadLocation(name)
+		if err != nil {
+			t.Skipf("time zone data unavailable: %v", err)
+		}
+		got := FormatUTCOffset(time.Date(2025, 7, 1, 12, 0, 0, 0, location))
+		if got != want {
+			t.Errorf("FormatUTCOffset(%s) = %s, want %s", name, got, want)
+		}
+		if _, err := ParseUTCOffset(got); err != nil {
+			t.Errorf("ParseUTCOffset(FormatUTCOffset(%s)) error = %v", name, err)
+		}
+	}
+}
+
+func TestDateAttributesRoundTrip(t *testing.T) {
+	seoul := time.FixedZone("KST", 9*3600)
+	start := time.Date(2025, 12, 25, 19, 0, 0, 0, seoul)
+	remind := time.Date(2025, 12, 25, 12, 0, 0, 0, time.FixedZone("IST", 5*3600+30*60))
+
+	card := newTestTicket().
+		SetStartDate(start).
+		AddRelatedCoupon(RelatedCoupon{Title: "Drink", NotificationTime: remind}).
+		Build()
+	attributes := card.Card.Data[0].Attributes
+
+	if got := attributes["relCoupon1.notificationTime.utcOffset"]; got != "UTC+05:30" {
+		t.Errorf("relCoupon1.notificationTime.utcOffset = %v, want UTC+05:30", got)
+	}
+	for key, want := range map[string]time.Time{"startDate": start, "relCoupon1.notificationTime": remind} {
+		got, ok, err := ParseDateAttribute(attributes, key)
+		if err != nil || !ok || !got.Equal(want) || got.Format("15:04") != want.Format("15:04") {
+			t.Errorf("ParseDateAttribute(%s) = %v, %v, %v, want %v", key, got, ok, err, want)
+		}
+	}
+	if errs := Validate(card); errs != nil {
+		t.Errorf("Validate() = %v", errs)
+	}
+
+	var typed TicketAttributes
+	applyRelatedCoupons(&typed, []RelatedCoupon{{NotificationTime: remind}})
+	got, err := typed.RelatedCouponNotificationTime(1)
+	if err != nil || got.Format(time.RFC3339) != remind.Format(time.RFC3339) {
+		t.Errorf("RelatedCouponNotificationTime(1) = %v, %v, want %v", got, err, remind)
+	}
+	if got, err := typed.RelatedCouponNotificationTime(2); err != nil || !got.IsZero() {
+		t.Errorf("RelatedCouponNotificationTime(2) = %v, %v, want zero", got, err)
+	}
+	if _, err := typed.RelatedCouponNotificationTime(4); err == nil {
+		t.Error("RelatedCouponNotificationTime(4) succeeded")
+	}
+}
+
+func TestValidateUTCOffsets(t *testing.T) {
+	card := validTicket()
+	attributes := card.Card.Data[0].Attributes
+	attributes["startDate"] = int64(1766656800000)
+	attributes["startDate.utcOffset"] = "UTC+14:59"
+	attributes["relCoupon2.notificationTime.utcOffset"] = "UTC+09:00"
+
+	fields := map[string]bool{}
+	for _, e := range Validate(card) {
+		fields[e.Field] = true
+	}
+	for _, want := range []string{
+		`card.data[0].attributes["startDate.utcOffset"]`,
+		`card.data[0].attributes["relCoupon2.notificationTime.utcOffset"]`,
+	} {
+		if !fields[want] {
+			t.Errorf("Validate() did not report %s: %v", want, fields)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package wallet
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Environment selects which Samsung Wallet server environment the client talks to
+type Environment string
+
+const (
+	EnvironmentSandbox    Environment = "sandbox"    // Samsung stage servers, for partner testing; needs Config.APIBaseURL
+	EnvironmentProduction Environment = "production" // Samsung production servers (default)
+)
+
+// EndpointResolver resolves the Samsung server API base URL for a request
+type EndpointResolver interface {
+	// ResolveEndpoint returns the base URL for the environment and cc2 country code
+	// countryCode may be empty for calls that are not country specific
+	ResolveEndpoint(env Environment, countryCode string) (string, error)
+}
+
+// EndpointResolverFunc adapts a function to the EndpointResolver interface
+type EndpointResolverFunc func(env Environment, countryCode string) (string, error)
+
+// ResolveEndpoint calls f(env, countryCode)
+func (f EndpointResolverFunc) ResolveEndpoint(env Environment, countryCode string) (string, error) {
+	return f(env, countryCode)
+}
+
+// StaticEndpoint returns a resolver that sends every request to baseURL
+// This is mainly useful for pointing the client at a local mock server in tests
+func StaticEndpoint(baseURL string) EndpointResolver {
+	baseURL = strings.TrimRight(baseURL, "/")
+	return EndpointResolverFunc(func(Environment, string) (string, error) {
+		return baseURL, nil
+	})
+}
+
+// productionHost is the Samsung server API host from the partner documentation
+// (Samsung Wallet API reference, "Send Card State" and "Add Card"), which routes
+// requests by the cc2 country code in the path
+const productionHost = "https://tsapi-card.walletsvc.samsung.com"
+
+// DefaultEndpointResolver returns the resolver that sends requests to Samsung's documented host
+func DefaultEndpointResolver() EndpointResolver {
+	return EndpointResolverFunc(resolveDefaultEndpoint)
+}
+
+// resolveDefaultEndpoint implements the default endpoint resolution
+// An empty environment means production, as before environments were configurable
+func resolveDefaultEndpoint(env Environment, countryCode string) (string, error) {
+	if countryCode != "" && !isCountryCode(countryCode) {
+		return "", fmt.Errorf("invalid country code %q: expected ISO 3166-1 alpha-2 (cc2)", countryCode)
+	}
+
+	switch env {
+	case "", EnvironmentProduction:
+		return productionHost, nil
+	case EnvironmentSandbox:
+		// Samsung does not publish a stage host; partners receive it with their account
+		return "", fmt.Errorf("no default host for the sandbox environment: set Config.APIBaseURL or an EndpointResolver")
+	default:
+		return "", fmt.Errorf("unsupported environment: %s", env)
+	}
+}
+
+// isCountryCode reports whether s looks like an ISO 3166-1 alpha-2 code
+func isCountryCode(s string) bool {
+	if len(s) != 2 {
+		return false
+	}
+	for _, r := range s {
+		if (r < 'A' || r > 'Z') && (r < 'a' || r > 'z') {
+			return false
+		}
+	}
+	return true
+}
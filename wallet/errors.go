@@ -0,0 +1,129 @@
+package wallet
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Error categories for Samsung Wallet API failures
+// Match them with errors.Is, e.g. errors.Is(err, wallet.ErrNotFound)
+var (
+	ErrAuthFailure = errors.New("samsung wallet: authentication failed")
+	ErrInvalidCard = errors.New("samsung wallet: invalid card")
+	ErrNotFound    = errors.New("samsung wallet: not found")
+	ErrRateLimited = errors.New("samsung wallet: rate limited")
+	ErrServerError = errors.New("samsung wallet: server error")
+)
+
+// APIError represents an error response from the Samsung Wallet API
+type APIError struct {
+	StatusCode int    `json:"-"`                 // HTTP status code
+	Code       string `json:"code"`              // Samsung result code
+	Message    string `json:"message"`           // Samsung result message
+	Details    string `json:"details,omitempty"` // Additional details, if any
+	RequestID  string `json:"-"`                 // x-request-id of the failed call
+	RawBody    []byte `json:"-"`                 // Response body as received
+}
+
+// apiErrorBody accepts both the Samsung server API and the legacy error formats
+type apiErrorBody struct {
+	ResultCode    string `json:"resultCode"`
+	ResultMessage string `json:"resultMessage"`
+	Code          string `json:"code"`
+	Message       string `json:"message"`
+	Details       string `json:"details"`
+}
+
+// newAPIError builds an APIError from a failed HTTP response
+// Bodies that are not JSON are kept in RawBody and the status text is used as message
+func newAPIError(statusCode int, requestID string, body []byte) *APIError {
+	apiError := &APIError{
+		StatusCode: statusCode,
+		RequestID:  requestID,
+		RawBody:    body,
+	}
+
+	var parsed apiErrorBody
+	if err := json.Unmarshal(body, &parsed); err == nil {
+		apiError.Code = firstNonEmpty(parsed.ResultCode, parsed.Code)
+		apiError.Message = firstNonEmpty(parsed.ResultMessage, parsed.Message)
+		apiError.Details = parsed.Details
+	}
+
+	if apiError.Message == "" {
+		apiError.Message = http.StatusText(statusCode)
+	}
+
+	return apiError
+}
+
+func (e *APIError) Error() string {
+	var b strings.Builder
+	b.WriteString("samsung wallet API error")
+
+	var parts []string
+	if e.StatusCode != 0 {
+		parts = append(parts, fmt.Sprintf("status %d", e.StatusCode))
+	}
+	if e.Code != "" {
+		parts = append(parts, "code "+e.Code)
+	}
+	if e.RequestID != "" {
+		parts = append(parts, "request "+e.RequestID)
+	}
+	if len(parts) > 0 {
+		b.WriteString(" (" + strings.Join(parts, ", ") + ")")
+	}
+
+	b.WriteString(": " + e.Message)
+	if e.Details != "" {
+		b.WriteString(": " + e.Details)
+	}
+	return b.String()
+}
+
+// Category returns the sentinel error matching the failure, or nil if none applies
+func (e *APIError) Category() error {
+	switch {
+	case e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden:
+		return ErrAuthFailure
+	case e.StatusCode == http.StatusNotFound:
+		return ErrNotFound
+	case e.StatusCode == http.StatusTooManyRequests:
+		return ErrRateLimited
+	case e.StatusCode == http.StatusBadRequest || e.StatusCode == http.StatusUnprocessableEntity:
+		return ErrInvalidCard
+	case e.StatusCode >= 500:
+		return ErrServerError
+	default:
+		return nil
+	}
+}
+
+// Is reports whether the error belongs to the target category
+func (e *APIError) Is(target error) bool {
+	category := e.Category()
+	return category != nil && category == target
+}
+
+// AsAPIError returns the APIError wrapped in err, if any
+func AsAPIError(err error) (*APIError, bool) {
+	var apiError *APIError
+	if errors.As(err, &apiError) {
+		return apiError, true
+	}
+	return nil, false
+}
+
+// firstNonEmpty returns the first non-empty string
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
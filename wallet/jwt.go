@@ -1,6 +1,7 @@
 package wallet
 
 import (
+	"context"
 	"crypto/rsa"
 	"crypto/x509"
 	"encoding/json"
@@ -123,6 +124,82 @@ func (j *JWTManager) CreateCDATA(cardData interface{}) (string, error) {
 	return tokenString, nil
 }
 
+// CreateCDATAWithContext creates a CDATA token, returning early if ctx is already done
+func (j *JWTManager) CreateCDATAWithContext(ctx context.Context, cardData interface{}) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	return j.CreateCDATA(cardData)
+}
+
+// CreateCDATABatch creates CDATA tokens for multiple cards in order
+// Generation stops at the first failure or as soon as ctx is cancelled, since
+// each token requires an RSA encryption and signature and large batches take a while
+func (j *JWTManager) CreateCDATABatch(ctx context.Context, cards []interface{}) ([]string, error) {
+	tokens := make([]string, 0, len(cards))
+	for i, card := range cards {
+		token, err := j.CreateCDATAWithContext(ctx, card)
+		if err != nil {
+			return tokens, fmt.Errorf("failed to create CDATA for card %d: %w", i, err)
+		}
+		tokens = append(tokens, token)
+	}
+	return tokens, nil
+}
+
+// AuthTokenPayload represents the payload of a partner authorization token
+type AuthTokenPayload struct {
+	API   AuthTokenAPI `json:"API"`
+	RefID string       `json:"refId,omitempty"`
+}
+
+// AuthTokenAPI identifies the server API call an authorization token is bound to
+type AuthTokenAPI struct {
+	Method string `json:"method"`
+	Path   string `json:"path"`
+}
+
+// CreateAuthToken creates the partner authorization token sent as a Bearer
+// credential on Samsung server API calls
+// The token is a JWS signed with the partner private key, carrying the same
+// Samsung headers as CDATA but with cty "AUTH", and is bound to one API call
+func (j *JWTManager) CreateAuthToken(method, path, refID string) (string, error) {
+	payload, err := json.Marshal(AuthTokenPayload{
+		API:   AuthTokenAPI{Method: method, Path: path},
+		RefID: refID,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal auth token payload: %v", err)
+	}
+
+	signer, err := jose.NewSigner(
+		jose.SigningKey{
+			Algorithm: jose.RS256,
+			Key:       j.partnerPrivateKey,
+		},
+		(&jose.SignerOptions{}).WithType("JWT").WithHeader("cty", "AUTH").
+			WithHeader("partnerId", j.partnerID).
+			WithHeader("ver", "3").
+			WithHeader("certificateId", j.certificateID).
+			WithHeader("utc", time.Now().UnixMilli()),
+	)
+	if err != nil {
+		return "", fmt.Errorf("failed to create JWS signer: %v", err)
+	}
+
+	jwsObject, err := signer.Sign(payload)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign auth token: %v", err)
+	}
+
+	tokenString, err := jwsObject.CompactSerialize()
+	if err != nil {
+		return "", fmt.Errorf("failed to serialize JWS object: %v", err)
+	}
+
+	return tokenString, nil
+}
+
 // CreateDataTransmitToken creates a token for data transmit link
 func (j *JWTManager) CreateDataTransmitToken(cardData interface{}) (string, error) {
 	// Data transmit uses CDATA format with 30-second expiration
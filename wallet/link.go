@@ -0,0 +1,209 @@
+package wallet
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// LinkType selects how Samsung Wallet obtains the card data of an ATW link
+type LinkType string
+
+const (
+	LinkTypeDataTransmit LinkType = "data_transmit" // Card data travels in the link as CDATA
+	LinkTypeDataFetch    LinkType = "data_fetch"    // Samsung fetches card data from the partner by refId
+)
+
+const (
+	// defaultLinkHost is the public Add to Samsung Wallet link host
+	defaultLinkHost = "https://a.swallet.link"
+
+	// defaultATWVersion is the ATW link version path segment
+	defaultATWVersion = "v3"
+
+	// defaultLinkFragment is the fragment name that precedes the link parameters
+	defaultLinkFragment = "Clip"
+
+	// cdataLifetime is how long Samsung accepts CDATA after it was generated
+	cdataLifetime = 30 * time.Second
+
+	// Link fragment parameters
+	linkParamCDATA    = "cdata"
+	linkParamPDATA    = "pdata"
+	linkParamCallback = "callbackUrl"
+	linkParamReferrer = "referrer"
+)
+
+// linkConfig holds the per-client ATW link format
+type linkConfig struct {
+	host     string // Link host without trailing slash
+	version  string // ATW version path segment, e.g. "v3"
+	fragment string // Fragment name, e.g. "Clip"
+}
+
+// newLinkConfig resolves the link format from the client configuration
+func newLinkConfig(config *Config) linkConfig {
+	// Samsung serves links for stage and production cards from the same public host;
+	// which environment a card belongs to is decided by its Partners Portal registration
+	host := firstNonEmpty(config.LinkBaseURL, config.BaseURL, defaultLinkHost)
+
+	return linkConfig{
+		host:     strings.TrimRight(host, "/"),
+		version:  strings.Trim(firstNonEmpty(config.ATWVersion, defaultATWVersion), "/"),
+		fragment: firstNonEmpty(config.LinkFragment, defaultLinkFragment),
+	}
+}
+
+// LinkOptions configures how an ATW link is created
+type LinkOptions struct {
+	Type        LinkType // Link type (default LinkTypeDataTransmit)
+	CallbackURL string   // URL the user is redirected to after adding the card
+	Referrer    string   // Referrer code used to attribute where the link was shared
+	Host        string   // Link host override for this link (default: the client's link host)
+	RefID       string   // refId sent as pdata in data fetch links (default: the card's refId)
+}
+
+// LinkOption configures LinkOptions
+type LinkOption func(*LinkOptions)
+
+// WithLinkType sets the link type
+func WithLinkType(linkType LinkType) LinkOption {
+	return func(o *LinkOptions) {
+		o.Type = linkType
+	}
+}
+
+// WithCallbackURL sets the URL the user is redirected to after adding the card
+func WithCallbackURL(callbackURL string) LinkOption {
+	return func(o *LinkOptions) {
+		o.CallbackURL = callbackURL
+	}
+}
+
+// WithReferrer sets the referrer code of the link
+func WithReferrer(referrer string) LinkOption {
+	return func(o *LinkOptions) {
+		o.Referrer = referrer
+	}
+}
+
+// WithLinkHost overrides the link host, e.g. "https://a.swallet.link"
+func WithLinkHost(host string) LinkOption {
+	return func(o *LinkOptions) {
+		o.Host = host
+	}
+}
+
+// WithRefID sets the refId of a data fetch link
+func WithRefID(refID string) LinkOption {
+	return func(o *LinkOptions) {
+		o.RefID = refID
+	}
+}
+
+// ATWLink represents a created Add to Samsung Wallet link
+type ATWLink struct {
+	URL           string    // Link to open on the device
+	Type          LinkType  // Link type
+	CardID        string    // Card ID from Partners Portal
+	CertificateID string    // Certificate ID, for data fetch links
+	RefID         string    // refId of the card instance
+	CDATA         string    // CDATA token, for data transmit links
+	ExpiresAt     time.Time // When the CDATA expires; zero for data fetch links, which don't expire
+}
+
+// Expired reports whether the link's CDATA has expired at t
+func (l *ATWLink) Expired(t time.Time) bool {
+	return !l.ExpiresAt.IsZero() && !t.Before(l.ExpiresAt)
+}
+
+// String returns the link URL
+func (l *ATWLink) String() string {
+	return l.URL
+}
+
+// CreateLink creates an Add to Samsung Wallet link for a WalletCard
+func (c *Client) CreateLink(cardID string, walletCard WalletCard, opts ...LinkOption) (*ATWLink, error) {
+	var refID string
+	if len(walletCard.Card.Data) > 0 {
+		refID = walletCard.Card.Data[0].RefID
+	}
+	return c.createLink(cardID, walletCard, refID, opts)
+}
+
+// createLink creates a link for any card payload accepted by CreateCDATA
+func (c *Client) createLink(cardID string, payload interface{}, refID string, opts []LinkOption) (*ATWLink, error) {
+	if cardID == "" {
+		return nil, fmt.Errorf("card ID is required (obtain from Partners Portal when registering card type)")
+	}
+
+	options := LinkOptions{Type: LinkTypeDataTransmit}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if options.Type == "" {
+		options.Type = LinkTypeDataTransmit
+	}
+	if options.RefID != "" {
+		refID = options.RefID
+	}
+
+	host := strings.TrimRight(options.Host, "/")
+	if host == "" {
+		host = c.linkConfig.host
+	}
+	atwPath := "/atw/" + c.linkConfig.version + "/"
+
+	link := &ATWLink{
+		Type:   options.Type,
+		CardID: cardID,
+		RefID:  refID,
+	}
+
+	var path, dataParam, dataValue string
+	switch options.Type {
+	case LinkTypeDataTransmit:
+		// Create CDATA token according to Samsung specification
+		// This generates a JWT with Samsung-specific headers and 30-second expiration
+		issuedAt := time.Now()
+		cdata, err := c.jwtManager.CreateCDATA(payload)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create CDATA token: %v", err)
+		}
+		link.CDATA = cdata
+		link.ExpiresAt = issuedAt.Add(cdataLifetime)
+
+		// URL format: https://a.swallet.link/atw/v3/{cardId}#Clip?cdata={cdata}
+		// cardId is the fixed identifier from Partners Portal (not individual card instance ID)
+		path = atwPath + url.PathEscape(cardID)
+		dataParam, dataValue = linkParamCDATA, cdata
+	case LinkTypeDataFetch:
+		// Data Fetch Link format: https://a.swallet.link/atw/v3/{certificateId}/{cardId}#Clip?pdata={pdata}
+		// pdata is the refId Samsung passes back to the partner's Get Card Data endpoint
+		if c.config.CertificateID == "" {
+			return nil, fmt.Errorf("certificate ID is required for data fetch links")
+		}
+		if refID == "" {
+			return nil, fmt.Errorf("ref ID is required for data fetch links")
+		}
+		link.CertificateID = c.config.CertificateID
+
+		path = atwPath + url.PathEscape(c.config.CertificateID) + "/" + url.PathEscape(cardID)
+		dataParam, dataValue = linkParamPDATA, refID
+	default:
+		return nil, fmt.Errorf("unsupported link type: %s", options.Type)
+	}
+
+	var fragment strings.Builder
+	fragment.WriteString(c.linkConfig.fragment + "?" + dataParam + "=" + url.QueryEscape(dataValue))
+	if options.CallbackURL != "" {
+		fragment.WriteString("&" + linkParamCallback + "=" + url.QueryEscape(options.CallbackURL))
+	}
+	if options.Referrer != "" {
+		fragment.WriteString("&" + linkParamReferrer + "=" + url.QueryEscape(options.Referrer))
+	}
+
+	link.URL = host + path + "#" + fragment.String()
+	return link, nil
+}
@@ -0,0 +1,153 @@
+package wallet
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-jose/go-jose/v3"
+)
+
+// CDATAHeader holds the JWS headers of a CDATA token
+type CDATAHeader struct {
+	Algorithm     string    // Signature algorithm, e.g. "RS256"
+	ContentType   string    // cty header, "CARD" for CDATA
+	PartnerID     string    // partnerId header
+	Version       string    // ver header
+	CertificateID string    // certificateId header
+	KeyID         string    // kid header, if any
+	UTC           time.Time // utc header: when the CDATA was created
+}
+
+// ParsedATWLink is an ATW link taken apart by ParseATWLink
+type ParsedATWLink struct {
+	ATWLink
+
+	Host        string        // Scheme and host, e.g. "https://a.swallet.link"
+	Version     string        // ATW version path segment, e.g. "v3"
+	Fragment    string        // Fragment name, e.g. "Clip"
+	CallbackURL string        // callbackUrl parameter
+	Referrer    string        // referrer parameter
+	Header      *CDATAHeader  // CDATA headers, for data transmit links
+	Age         time.Duration // Time since the CDATA was created, as of parsing
+}
+
+// ParseATWLink takes an Add to Samsung Wallet link apart without verifying its CDATA
+// Data transmit links yield the CDATA and its decoded JWS headers; data fetch links
+// yield the certificate ID and the refId sent as pdata
+func ParseATWLink(rawURL string) (*ParsedATWLink, error) {
+	// Chat clients often wrap pasted links in angle brackets
+	rawURL = strings.Trim(strings.TrimSpace(rawURL), "<>")
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse link: %v", err)
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("link must be an absolute URL")
+	}
+
+	segments := strings.Split(strings.Trim(u.EscapedPath(), "/"), "/")
+	if len(segments) < 3 || segments[0] != "atw" {
+		return nil, fmt.Errorf("not an ATW link: path must be /atw/{version}/...")
+	}
+	for i, segment := range segments {
+		if segments[i], err = url.PathUnescape(segment); err != nil {
+			return nil, fmt.Errorf("failed to unescape path: %v", err)
+		}
+	}
+
+	link := &ParsedATWLink{
+		ATWLink: ATWLink{URL: rawURL},
+		Host:    u.Scheme + "://" + u.Host,
+		Version: segments[1],
+	}
+
+	// Parameters follow the fragment name, e.g. #Clip?cdata=...
+	rawParams := u.RawQuery
+	if fragment := u.EscapedFragment(); fragment != "" {
+		name, params, _ := strings.Cut(fragment, "?")
+		link.Fragment = name
+		rawParams = params
+	}
+	params, err := url.ParseQuery(rawParams)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse link parameters: %v", err)
+	}
+	link.CallbackURL = params.Get(linkParamCallback)
+	link.Referrer = params.Get(linkParamReferrer)
+
+	switch ids := segments[2:]; {
+	case len(ids) == 1 && params.Has(linkParamCDATA):
+		link.Type = LinkTypeDataTransmit
+		link.CardID = ids[0]
+		link.CDATA = params.Get(linkParamCDATA)
+
+		header, err := ParseCDATAHeader(link.CDATA)
+		if err != nil {
+			return nil, err
+		}
+		link.Header = header
+		link.CertificateID = header.CertificateID
+		if !header.UTC.IsZero() {
+			link.ExpiresAt = header.UTC.Add(cdataLifetime)
+			link.Age = time.Since(header.UTC)
+		}
+	case len(ids) == 2 && params.Has(linkParamPDATA):
+		link.Type = LinkTypeDataFetch
+		link.CertificateID = ids[0]
+		link.CardID = ids[1]
+		link.RefID = params.Get(linkParamPDATA)
+	case params.Has(linkParamCDATA), params.Has(linkParamPDATA):
+		return nil, fmt.Errorf("unexpected path for %s link: %s", linkTypeOf(params), u.EscapedPath())
+	default:
+		return nil, fmt.Errorf("link has neither %s nor %s parameter", linkParamCDATA, linkParamPDATA)
+	}
+
+	return link, nil
+}
+
+// linkTypeOf returns the link type implied by the link parameters
+func linkTypeOf(params url.Values) LinkType {
+	if params.Has(linkParamCDATA) {
+		return LinkTypeDataTransmit
+	}
+	return LinkTypeDataFetch
+}
+
+// ParseCDATAHeader decodes the JWS headers of a CDATA token without verifying its signature
+func ParseCDATAHeader(cdata string) (*CDATAHeader, error) {
+	jws, err := jose.ParseSigned(cdata)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse CDATA: %v", err)
+	}
+	if len(jws.Signatures) == 0 {
+		return nil, fmt.Errorf("CDATA has no signature")
+	}
+	protected := jws.Signatures[0].Protected
+
+	header := &CDATAHeader{
+		Algorithm: protected.Algorithm,
+		KeyID:     protected.KeyID,
+	}
+	extra := protected.ExtraHeaders
+	header.ContentType, _ = extra[jose.HeaderContentType].(string)
+	header.PartnerID, _ = extra["partnerId"].(string)
+	header.Version, _ = extra["ver"].(string)
+	header.CertificateID, _ = extra["certificateId"].(string)
+
+	switch utc := extra["utc"].(type) {
+	case float64:
+		header.UTC = time.UnixMilli(int64(utc))
+	case json.Number:
+		ms, err := utc.Int64()
+		if err != nil {
+			return nil, fmt.Errorf("invalid utc header: %v", err)
+		}
+		header.UTC = time.UnixMilli(ms)
+	}
+
+	return header, nil
+}
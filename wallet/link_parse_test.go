@@ -0,0 +1,102 @@
+package wallet_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/abyssparanoia/samsung-wallet-go/wallet"
+	"github.com/abyssparanoia/samsung-wallet-go/wallet/testkeys"
+)
+
+func TestParseATWLinkDataTransmit(t *testing.T) {
+	keys := testkeys.MustGenerate()
+	client, err := keys.Client()
+	if err != nil {
+		t.Fatalf("Client() error = %v", err)
+	}
+	before := time.Now()
+
+	created, err := client.CreateLink("card-1", storedCard("card-1").Card,
+		wallet.WithCallbackURL("https://example.com/callback?a=1"), wallet.WithReferrer("newsletter"))
+	if err != nil {
+		t.Fatalf("CreateLink() error = %v", err)
+	}
+
+	link, err := wallet.ParseATWLink(created.URL)
+	if err != nil {
+		t.Fatalf("ParseATWLink() error = %v", err)
+	}
+	if link.Type != wallet.LinkTypeDataTransmit || link.CardID != "card-1" || link.CDATA != created.CDATA {
+		t.Errorf("ParseATWLink() = %+v, want the data transmit link for card-1", link)
+	}
+	if link.Host != "https://a.swallet.link" || link.Version != "v3" || link.Fragment != "Clip" {
+		t.Errorf("ParseATWLink() host, version, fragment = %q, %q, %q", link.Host, link.Version, link.Fragment)
+	}
+	if link.CallbackURL != "https://example.com/callback?a=1" || link.Referrer != "newsletter" {
+		t.Errorf("ParseATWLink() callback, referrer = %q, %q", link.CallbackURL, link.Referrer)
+	}
+
+	header := link.Header
+	if header == nil || header.ContentType != "CARD" || header.PartnerID != keys.PartnerID || header.CertificateID != keys.CertificateID {
+		t.Fatalf("Header = %+v, want CARD headers of %s", header, keys.PartnerID)
+	}
+	if link.CertificateID != header.CertificateID {
+		t.Errorf("CertificateID = %q, want %q", link.CertificateID, header.CertificateID)
+	}
+
+	// utc is in milliseconds, so it may round down below before
+	if header.UTC.Before(before.Add(-time.Millisecond)) || header.UTC.After(time.Now()) {
+		t.Errorf("Header.UTC = %v, want the time of CreateLink", header.UTC)
+	}
+	if link.Age < 0 || link.Age > time.Minute {
+		t.Errorf("Age = %v, want the time since CreateLink", link.Age)
+	}
+	if !link.ExpiresAt.Equal(header.UTC.Add(30 * time.Second)) {
+		t.Errorf("ExpiresAt = %v, want utc + 30s", link.ExpiresAt)
+	}
+}
+
+func TestParseATWLinkDataFetch(t *testing.T) {
+	client := newRedirectClient(t)
+	created, err := client.CreateLink("card-1", storedCard("card-1").Card,
+		wallet.WithLinkType(wallet.LinkTypeDataFetch), wallet.WithCallbackURL("https://example.com/cb"))
+	if err != nil {
+		t.Fatalf("CreateLink() error = %v", err)
+	}
+
+	// Pasted links are often wrapped in angle brackets
+	for _, raw := range []string{created.URL, "<" + created.URL + ">", " <" + created.URL + ">\n"} {
+		link, err := wallet.ParseATWLink(raw)
+		if err != nil {
+			t.Fatalf("ParseATWLink(%q) error = %v", raw, err)
+		}
+		if link.Type != wallet.LinkTypeDataFetch || link.CardID != "card-1" || link.RefID != "ref-1" ||
+			link.CertificateID != testkeys.DefaultCertificateID || link.CallbackURL != "https://example.com/cb" {
+			t.Errorf("ParseATWLink(%q) = %+v, want the data fetch link for card-1", raw, link)
+		}
+		if link.Header != nil || !link.ExpiresAt.IsZero() {
+			t.Errorf("ParseATWLink(%q) has CDATA details: %+v", raw, link)
+		}
+	}
+}
+
+func TestParseATWLinkErrors(t *testing.T) {
+	tests := map[string]struct {
+		url  string
+		want string
+	}{
+		"relative":          {"/atw/v3/card#Clip?pdata=ref", "absolute URL"},
+		"not atw":           {"https://a.swallet.link/add/v3/card#Clip?pdata=ref", "not an ATW link"},
+		"too short":         {"https://a.swallet.link/atw/v3#Clip?pdata=ref", "not an ATW link"},
+		"fetch without id":  {"https://a.swallet.link/atw/v3/card#Clip?pdata=ref", "unexpected path for data_fetch link"},
+		"transmit with ids": {"https://a.swallet.link/atw/v3/cert/card#Clip?cdata=x", "unexpected path for data_transmit link"},
+		"no data":           {"https://a.swallet.link/atw/v3/card#Clip?callbackUrl=x", "neither cdata nor pdata"},
+		"bad cdata":         {"https://a.swallet.link/atw/v3/card#Clip?cdata=garbage", "failed to parse CDATA"},
+	}
+	for name, tt := range tests {
+		if _, err := wallet.ParseATWLink(tt.url); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: ParseATWLink() error = %v, want %q", name, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,243 @@
+package wallet
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// languageTagPattern matches well-formed BCP 47 (RFC 5646) language tags
+var languageTagPattern = regexp.MustCompile(`(?i)^(?:` +
+	`(?:[a-z]{2,3}(?:-[a-z]{3}){0,3}|[a-z]{4}|[a-z]{5,8})` + // language
+	`(?:-[a-z]{4})?` + // script
+	`(?:-(?:[a-z]{2}|[0-9]{3}))?` + // region
+	`(?:-(?:[a-z0-9]{5,8}|[0-9][a-z0-9]{3}))*` + // variants
+	`(?:-[0-9a-wy-z](?:-[a-z0-9]{2,8})+)*` + // extensions
+	`(?:-x(?:-[a-z0-9]{1,8})+)?` + // private use
+	`|x(?:-[a-z0-9]{1,8})+)$`)
+
+// ValidateLanguageTag checks that tag is a well-formed BCP 47 language tag, e.g. "ko" or "en-US"
+func ValidateLanguageTag(tag string) error {
+	if !languageTagPattern.MatchString(tag) {
+		return fmt.Errorf("invalid BCP 47 language tag: %q", tag)
+	}
+	return nil
+}
+
+// TicketLocalization holds the localizable attributes of an event ticket
+type TicketLocalization struct {
+	Title             string `json:"title,omitempty"`
+	Subtitle1         string `json:"subtitle1,omitempty"`
+	ProviderName      string `json:"providerName,omitempty"`
+	MainImg           string `json:"mainImg,omitempty"`
+	LogoImage         string `json:"logoImage,omitempty"`
+	HolderName        string `json:"holderName,omitempty"`
+	Grade             string `json:"grade,omitempty"`
+	SeatClass         string `json:"seatClass,omitempty"`
+	Entrance          string `json:"entrance,omitempty"`
+	SeatNumber        string `json:"seatNumber,omitempty"`
+	User              string `json:"user,omitempty"`
+	Certification     string `json:"certification,omitempty"`
+	NoticeDesc        string `json:"noticeDesc,omitempty"`
+	GroupInfo1        string `json:"groupInfo1,omitempty"`
+	GroupInfo2        string `json:"groupInfo2,omitempty"`
+	GroupInfo3        string `json:"groupInfo3,omitempty"`
+	AppLinkName       string `json:"appLinkName,omitempty"`
+	ReservationNumber string `json:"reservationNumber,omitempty"`
+}
+
+// attributes returns the localization as an attribute map
+func (l TicketLocalization) attributes() (map[string]interface{}, error) {
+	jsonData, err := json.Marshal(l)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal localization: %v", err)
+	}
+	var attributes map[string]interface{}
+	if err := json.Unmarshal(jsonData, &attributes); err != nil {
+		return nil, fmt.Errorf("failed to convert localization: %v", err)
+	}
+	return attributes, nil
+}
+
+// AddTicketLocalization adds typed localized attributes for a BCP 47 language
+// An invalid language tag is reported by Validate and BuildStrict
+func (b *EventTicketBuilder) AddTicketLocalization(language string, localization TicketLocalization) *EventTicketBuilder {
+	attributes, err := localization.attributes()
+	if err != nil {
+		b.errs = append(b.errs, err)
+		return b
+	}
+	return b.AddLocalization(language, attributes)
+}
+
+// AddLocalizationsFromCatalog adds one localization per catalog language, in language order
+// Invalid language tags are reported by Validate and BuildStrict
+func (b *EventTicketBuilder) AddLocalizationsFromCatalog(catalog LocalizationCatalog) *EventTicketBuilder {
+	for _, language := range catalog.Languages() {
+		attributes := make(map[string]interface{}, len(catalog[language]))
+		for key, value := range catalog[language] {
+			attributes[key] = value
+		}
+		b.AddLocalization(language, attributes)
+	}
+	return b
+}
+
+// LocalizationCatalog holds translated attribute values by language and attribute key
+type LocalizationCatalog map[string]map[string]string
+
+// Languages returns the catalog languages in sorted order
+func (c LocalizationCatalog) Languages() []string {
+	languages := make([]string, 0, len(c))
+	for language := range c {
+		languages = append(languages, language)
+	}
+	sort.Strings(languages)
+	return languages
+}
+
+// Merge adds the translations of other, overriding existing ones
+func (c LocalizationCatalog) Merge(other LocalizationCatalog) {
+	for language, messages := range other {
+		if c[language] == nil {
+			c[language] = make(map[string]string, len(messages))
+		}
+		for key, value := range messages {
+			c[language][key] = value
+		}
+	}
+}
+
+// LoadCatalogFile loads a catalog from a .json or .po file
+func LoadCatalogFile(path string) (LocalizationCatalog, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open catalog: %v", err)
+	}
+	defer f.Close()
+
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".json":
+		return ParseJSONCatalog(f)
+	case ".po":
+		return ParsePOCatalog(f)
+	default:
+		return nil, fmt.Errorf("unsupported catalog format: %s", ext)
+	}
+}
+
+// ParseJSONCatalog parses a JSON catalog of the form {"ko": {"title": "..."}}
+func ParseJSONCatalog(r io.Reader) (LocalizationCatalog, error) {
+	var catalog LocalizationCatalog
+	if err := json.NewDecoder(r).Decode(&catalog); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON catalog: %v", err)
+	}
+	return catalog, nil
+}
+
+// ParsePOCatalog parses a gettext PO file for one language, taken from its Language header
+// Entries are keyed by msgctxt when present, otherwise by msgid, so either can hold the
+// attribute key; fuzzy and untranslated entries are skipped
+func ParsePOCatalog(r io.Reader) (LocalizationCatalog, error) {
+	var (
+		language string
+		messages = make(map[string]string)
+		entry    poEntry
+		field    *string // Field that continuation lines append to
+	)
+
+	// flush stores the current entry once its msgstr has been read
+	flush := func() {
+		if !entry.translated {
+			return
+		}
+		switch {
+		case entry.msgid == "" && entry.msgctxt == "":
+			// Header entry
+			for _, line := range strings.Split(entry.msgstr, "\n") {
+				if name, value, ok := strings.Cut(line, ":"); ok && strings.EqualFold(strings.TrimSpace(name), "Language") {
+					language = strings.ReplaceAll(strings.TrimSpace(value), "_", "-")
+				}
+			}
+		case entry.fuzzy || entry.msgstr == "":
+		case entry.msgctxt != "":
+			messages[entry.msgctxt] = entry.msgstr
+		default:
+			messages[entry.msgid] = entry.msgstr
+		}
+		entry, field = poEntry{}, nil
+	}
+
+	scanner := bufio.NewScanner(r)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+
+		switch {
+		case line == "":
+			flush()
+			continue
+		case strings.HasPrefix(line, "#"):
+			flush()
+			if strings.HasPrefix(line, "#,") && strings.Contains(line, "fuzzy") {
+				entry.fuzzy = true
+			}
+			continue
+		}
+
+		keyword, rest, _ := strings.Cut(line, " ")
+		if strings.HasPrefix(line, `"`) {
+			keyword, rest = "", line
+		}
+
+		value, err := strconv.Unquote(strings.TrimSpace(rest))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse PO catalog at line %d: invalid string %s", lineNumber, rest)
+		}
+
+		switch keyword {
+		case "":
+			if field == nil {
+				return nil, fmt.Errorf("failed to parse PO catalog at line %d: unexpected string", lineNumber)
+			}
+			*field += value
+		case "msgctxt", "msgid":
+			flush()
+			if keyword == "msgctxt" {
+				entry.msgctxt, field = value, &entry.msgctxt
+			} else {
+				entry.msgid, field = value, &entry.msgid
+			}
+		case "msgstr":
+			entry.msgstr, field = value, &entry.msgstr
+			entry.translated = true
+		default:
+			// Plural forms are not used for attributes
+			field = nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read PO catalog: %v", err)
+	}
+	flush()
+
+	if language == "" {
+		return nil, fmt.Errorf("PO catalog has no Language header")
+	}
+	return LocalizationCatalog{language: messages}, nil
+}
+
+// poEntry is one PO catalog entry being parsed
+type poEntry struct {
+	msgctxt    string
+	msgid      string
+	msgstr     string
+	fuzzy      bool
+	translated bool // msgstr was read
+}
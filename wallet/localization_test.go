@@ -0,0 +1,135 @@
+package wallet
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestValidateLanguageTag(t *testing.T) {
+	for _, tag := range []string{"ko", "en-US", "zh-Hant-TW", "es-419", "de-CH-1996", "x-private", "en-US-u-ca-gregory"} {
+		if err := ValidateLanguageTag(tag); err != nil {
+			t.Errorf("ValidateLanguageTag(%q) error = %v", tag, err)
+		}
+	}
+	for _, tag := range []string{"", "en_US", "e", "en-", "toolonglanguage", "en-US-"} {
+		if err := ValidateLanguageTag(tag); err == nil {
+			t.Errorf("ValidateLanguageTag(%q) succeeded", tag)
+		}
+	}
+}
+
+func TestParsePOCatalog(t *testing.T) {
+	po := `# Korean translations
+msgid ""
+msgstr ""
+"Content-Type: text/plain; charset=UTF-8\n"
+"Language: ko_KR\n"
+
+msgid "title"
+msgstr "콘서트"
+
+#. Entries keyed by context
+msgctxt "providerName"
+msgid "Live Nation"
+msgstr "라이브 "
+"네이션"
+
+#, fuzzy
+msgid "grade"
+msgstr "VIP석"
+
+msgid "seatClass"
+msgstr ""
+
+msgid "noticeDesc"
+msgstr "<b>\"입장\"</b>\n안내"
+`
+	catalog, err := ParsePOCatalog(strings.NewReader(po))
+	if err != nil {
+		t.Fatalf("ParsePOCatalog() error = %v", err)
+	}
+	want := LocalizationCatalog{"ko-KR": {
+		"title":        "콘서트",
+		"providerName": "라이브 네이션",
+		"noticeDesc":   "<b>\"입장\"</b>\n안내",
+	}}
+	if !reflect.DeepEqual(catalog, want) {
+		t.Errorf("ParsePOCatalog() = %v, want %v", catalog, want)
+	}
+}
+
+func TestParsePOCatalogErrors(t *testing.T) {
+	tests := map[string]string{
+		"no language":     "msgid \"title\"\nmsgstr \"x\"\n",
+		"bad string":      "msgid \"\"\nmsgstr \"Language: ko\\n\"\n\nmsgid title\n",
+		"stray string":    "\"Language: ko\\n\"\n",
+		"unterminated":    "msgid \"\"\nmsgstr \"Language: ko\n",
+		"empty catalog":   "",
+		"comment only":    "# nothing here\n",
+		"language no msg": "msgid \"\"\nmsgstr \"Project: x\\n\"\n",
+	}
+	for name, po := range tests {
+		if _, err := ParsePOCatalog(strings.NewReader(po)); err == nil {
+			t.Errorf("%s: ParsePOCatalog() succeeded", name)
+		}
+	}
+}
+
+func TestLocalizationCatalogMerge(t *testing.T) {
+	catalog := LocalizationCatalog{"ko": {"title": "콘서트", "grade": "A"}}
+	catalog.Merge(LocalizationCatalog{"ko": {"grade": "VIP"}, "ja": {"title": "コンサート"}})
+
+	want := LocalizationCatalog{"ko": {"title": "콘서트", "grade": "VIP"}, "ja": {"title": "コンサート"}}
+	if !reflect.DeepEqual(catalog, want) {
+		t.Errorf("Merge() = %v, want %v", catalog, want)
+	}
+	if got := catalog.Languages(); !reflect.DeepEqual(got, []string{"ja", "ko"}) {
+		t.Errorf("Languages() = %v, want [ja ko]", got)
+	}
+}
+
+func TestBuildStrictReportsLanguageOnce(t *testing.T) {
+	_, err := newTestTicket().
+		AddTicketLocalization("ko_KR", TicketLocalization{Title: "콘서트"}).
+		AddLocalizationsFromCatalog(LocalizationCatalog{"ja_JP": {"title": "コンサート"}}).
+		BuildStrict()
+	if err == nil {
+		t.Fatal("BuildStrict() succeeded")
+	}
+
+	for _, tag := range []string{"ko_KR", "ja_JP"} {
+		if got := strings.Count(err.Error(), `"`+tag+`"`); got != 1 {
+			t.Errorf("BuildStrict() reports %s %d times, want once: %v", tag, got, err)
+		}
+	}
+	var validationErr ValidationError
+	if !errors.As(err, &validationErr) || !strings.HasSuffix(validationErr.Field, ".language") {
+		t.Errorf("BuildStrict() error = %v, want a language ValidationError", err)
+	}
+}
+
+func TestValidateLocalizedKeys(t *testing.T) {
+	card := newTestTicket().
+		AddRelatedCoupon(RelatedCoupon{Title: "Free drink"}).
+		AddLocalization("ko", map[string]interface{}{
+			"title":            "콘서트",
+			"relCoupon1.title": "무료 음료",
+			"tittle":           "오타",
+			"grade":            "VIP석",
+		}).
+		Build()
+
+	var fields []string
+	for _, e := range Validate(card) {
+		fields = append(fields, e.Field+": "+e.Message)
+	}
+	want := []string{
+		"card.data[0].localization[0].attributes.grade: is not set in the base attributes",
+		"card.data[0].localization[0].attributes.tittle: is not a ticket attribute",
+	}
+	if !reflect.DeepEqual(fields, want) {
+		t.Errorf("Validate() = %q, want %q", fields, want)
+	}
+}
@@ -0,0 +1,244 @@
+package wallet
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+)
+
+// Samsung server notification API
+
+// UpdateNotificationRequest tells Samsung Wallet that a card added by a user has changed
+//
+// By default Samsung Wallet reacts by re-fetching the card through the partner's
+// Get Card Data endpoint. Setting Card sends the new card data inline instead.
+type UpdateNotificationRequest struct {
+	CardID      string      // Card ID from Partners Portal (identifies the card type registration)
+	CountryCode string      // cc2 country code the card was added in
+	RefID       string      // refId of the card instance that changed
+	CardType    string      // Samsung card type, e.g. "ticket" (defaults to Card.Card.Type)
+	State       CardState   // Optional new state of the card, e.g. CardStateUpdated
+	Card        *WalletCard // Optional inline card data for RefID
+}
+
+// NotificationResult represents Samsung's response to a notification API call
+type NotificationResult struct {
+	ResultCode    string `json:"resultCode"`
+	ResultMessage string `json:"resultMessage"`
+}
+
+// Success reports whether Samsung accepted the notification
+func (r *NotificationResult) Success() bool {
+	return r.ResultCode == "" || r.ResultCode == "0" || r.ResultCode == "SUCCESS"
+}
+
+// CancelReason describes why cards are being cancelled
+type CancelReason string
+
+const (
+	CancelReasonEventCanceled  CancelReason = "EVENT_CANCELED"  // The event will not take place
+	CancelReasonEventPostponed CancelReason = "EVENT_POSTPONED" // The event moved and tickets are reissued
+	CancelReasonRefunded       CancelReason = "REFUNDED"        // The purchase was refunded
+	CancelReasonOther          CancelReason = "OTHER"           // Any other reason
+)
+
+// CancelNotificationRequest tells Samsung Wallet that cards were cancelled
+//
+// All cards tied to EventID are cancelled unless RefIDs narrows the notification
+// to specific card instances.
+type CancelNotificationRequest struct {
+	CardID      string       // Card ID from Partners Portal (identifies the card type registration)
+	CountryCode string       // cc2 country code the cards were added in
+	CardType    string       // Samsung card type (defaults to "ticket")
+	EventID     string       // eventId shared by the affected cards
+	RefIDs      []string     // Optional refIds to cancel instead of every card of the event
+	Reason      CancelReason // Why the cards were cancelled
+}
+
+// CancelNotificationResult represents Samsung's response to a cancel notification
+type CancelNotificationResult struct {
+	NotificationResult
+	Cards []CanceledCard `json:"data,omitempty"` // Cards Samsung marked as cancelled
+}
+
+// CanceledCard identifies a card affected by a cancel notification
+type CanceledCard struct {
+	RefID   string    `json:"refId"`
+	EventID string    `json:"eventId,omitempty"`
+	State   CardState `json:"state"`
+}
+
+// notificationBody is the wire format of update and cancel notifications
+type notificationBody struct {
+	Card notificationCard `json:"card"`
+}
+
+// notificationCard is the card envelope of a notification
+type notificationCard struct {
+	Type    string             `json:"type"`
+	SubType string             `json:"subType,omitempty"`
+	Data    []notificationData `json:"data"`
+}
+
+// notificationData identifies one card instance in a notification
+type notificationData struct {
+	RefID        string                   `json:"refId,omitempty"`
+	EventID      string                   `json:"eventId,omitempty"`
+	State        CardState                `json:"state,omitempty"`
+	Reason       CancelReason             `json:"reason,omitempty"`
+	CreatedAt    int64                    `json:"createdAt,omitempty"`
+	UpdatedAt    int64                    `json:"updatedAt,omitempty"`
+	Language     string                   `json:"language,omitempty"`
+	Attributes   WalletCardAttributes     `json:"attributes,omitempty"`
+	Localization []WalletCardLocalization `json:"localization,omitempty"`
+}
+
+// SendUpdateNotification notifies Samsung Wallet that the card identified by RefID changed
+func (c *Client) SendUpdateNotification(ctx context.Context, request *UpdateNotificationRequest) (*NotificationResult, error) {
+	if request == nil {
+		return nil, fmt.Errorf("update notification request cannot be nil")
+	}
+	if request.CardID == "" {
+		return nil, fmt.Errorf("card ID is required (obtain from Partners Portal when registering card type)")
+	}
+	if request.CountryCode == "" {
+		return nil, fmt.Errorf("country code is required")
+	}
+	if request.RefID == "" {
+		return nil, fmt.Errorf("ref ID is required")
+	}
+
+	body := notificationBody{
+		Card: notificationCard{Type: request.CardType},
+	}
+
+	data := notificationData{
+		RefID: request.RefID,
+		State: request.State,
+	}
+
+	if request.Card != nil {
+		if body.Card.Type == "" {
+			body.Card.Type = request.Card.Card.Type
+		}
+		body.Card.SubType = request.Card.Card.SubType
+
+		inline, ok := findCardData(request.Card, request.RefID)
+		if !ok {
+			return nil, fmt.Errorf("inline card data does not contain ref ID %s", request.RefID)
+		}
+		data.CreatedAt = inline.CreatedAt
+		data.UpdatedAt = inline.UpdatedAt
+		data.Language = inline.Language
+		data.Attributes = inline.Attributes
+		data.Localization = inline.Localization
+	}
+
+	if body.Card.Type == "" {
+		return nil, fmt.Errorf("card type is required")
+	}
+	body.Card.Data = []notificationData{data}
+
+	response, err := c.makeAPIRequest(ctx, apiRequest{
+		method:      "POST",
+		path:        fmt.Sprintf(pathUpdateNotification, url.PathEscape(request.CountryCode), url.PathEscape(request.CardID)),
+		countryCode: request.CountryCode,
+		refID:       request.RefID,
+		payload:     body,
+		idempotent:  true, // Samsung re-fetches the latest card data, so repeats are harmless
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return parseNotificationResult(response)
+}
+
+// SendCancelNotification notifies Samsung Wallet that every card of an event,
+// or the listed refIds, were cancelled
+func (c *Client) SendCancelNotification(ctx context.Context, request *CancelNotificationRequest) (*CancelNotificationResult, error) {
+	if request == nil {
+		return nil, fmt.Errorf("cancel notification request cannot be nil")
+	}
+	if request.CardID == "" {
+		return nil, fmt.Errorf("card ID is required (obtain from Partners Portal when registering card type)")
+	}
+	if request.CountryCode == "" {
+		return nil, fmt.Errorf("country code is required")
+	}
+	if request.EventID == "" && len(request.RefIDs) == 0 {
+		return nil, fmt.Errorf("event ID or ref IDs are required")
+	}
+
+	cardType := request.CardType
+	if cardType == "" {
+		cardType = "ticket"
+	}
+
+	var data []notificationData
+	if len(request.RefIDs) == 0 {
+		data = []notificationData{{
+			EventID: request.EventID,
+			State:   CardStateCanceled,
+			Reason:  request.Reason,
+		}}
+	} else {
+		data = make([]notificationData, 0, len(request.RefIDs))
+		for _, refID := range request.RefIDs {
+			if refID == "" {
+				return nil, fmt.Errorf("ref IDs cannot contain empty values")
+			}
+			data = append(data, notificationData{
+				RefID:   refID,
+				EventID: request.EventID,
+				State:   CardStateCanceled,
+				Reason:  request.Reason,
+			})
+		}
+	}
+
+	response, err := c.makeAPIRequest(ctx, apiRequest{
+		method:      "POST",
+		path:        fmt.Sprintf(pathCancelNotification, url.PathEscape(request.CountryCode), url.PathEscape(request.CardID)),
+		countryCode: request.CountryCode,
+		payload: notificationBody{
+			Card: notificationCard{Type: cardType, Data: data},
+		},
+		idempotent: true, // Cancelling already cancelled cards is a no-op
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &CancelNotificationResult{}
+	if len(response) > 0 {
+		if err := json.Unmarshal(response, result); err != nil {
+			return nil, fmt.Errorf("failed to parse cancel notification result: %v", err)
+		}
+	}
+	return result, nil
+}
+
+// findCardData returns the card data entry with the given refId
+func findCardData(card *WalletCard, refID string) (WalletCardData, bool) {
+	for _, data := range card.Card.Data {
+		if data.RefID == refID {
+			return data, true
+		}
+	}
+	return WalletCardData{}, false
+}
+
+// parseNotificationResult parses a notification response body
+func parseNotificationResult(response []byte) (*NotificationResult, error) {
+	result := &NotificationResult{}
+	if len(response) == 0 {
+		return result, nil
+	}
+
+	if err := json.Unmarshal(response, result); err != nil {
+		return nil, fmt.Errorf("failed to parse notification result: %v", err)
+	}
+	return result, nil
+}
@@ -0,0 +1,56 @@
+package wallet
+
+import (
+	"fmt"
+
+	"github.com/abyssparanoia/samsung-wallet-go/wallet/qrcode"
+)
+
+// QRCode encodes the link URL as a QR code
+// Data transmit links carry CDATA and are long; use qrcode.Low or qrcode.Medium
+// to keep the symbol scannable from printed media
+func (l *ATWLink) QRCode(level qrcode.Level) (*qrcode.Code, error) {
+	return LinkQRCode(l.URL, level)
+}
+
+// LinkQRCode encodes any ATW link URL as a QR code
+func LinkQRCode(link string, level qrcode.Level) (*qrcode.Code, error) {
+	if link == "" {
+		return nil, fmt.Errorf("link is required")
+	}
+
+	code, err := qrcode.Encode(link, level)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode link as QR code: %v", err)
+	}
+	return code, nil
+}
+
+// BarcodeQRCode encodes the barcode.value attribute of a card as a QR code
+// The card's barcode.errorCorrectionLevel attribute is used when set, otherwise defaultLevel
+func BarcodeQRCode(walletCard WalletCard, defaultLevel qrcode.Level) (*qrcode.Code, error) {
+	if len(walletCard.Card.Data) == 0 {
+		return nil, fmt.Errorf("card has no data")
+	}
+	attributes := walletCard.Card.Data[0].Attributes
+
+	value, _ := attributes["barcode.value"].(string)
+	if value == "" {
+		return nil, fmt.Errorf("card has no barcode.value attribute")
+	}
+
+	level := defaultLevel
+	if name, _ := attributes["barcode.errorCorrectionLevel"].(string); name != "" {
+		parsed, err := qrcode.ParseLevel(name)
+		if err != nil {
+			return nil, fmt.Errorf("invalid barcode.errorCorrectionLevel: %v", err)
+		}
+		level = parsed
+	}
+
+	code, err := qrcode.Encode(value, level)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode barcode as QR code: %v", err)
+	}
+	return code, nil
+}
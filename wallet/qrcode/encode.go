@@ -0,0 +1,268 @@
+// Package qrcode is a dependency-free QR code encoder with PNG and SVG rendering
+package qrcode
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Level is the QR code error correction level
+type Level int
+
+const (
+	Low      Level = iota // Recovers ~7% of damaged codewords
+	Medium                // Recovers ~15% of damaged codewords
+	Quartile              // Recovers ~25% of damaged codewords
+	High                  // Recovers ~30% of damaged codewords
+)
+
+const (
+	minVersion = 1
+	maxVersion = 40
+)
+
+// ErrTooLong is returned when the content does not fit in a version 40 symbol
+var ErrTooLong = errors.New("qrcode: content too long")
+
+// ParseLevel parses an error correction level name: L, M, Q or H
+func ParseLevel(s string) (Level, error) {
+	switch strings.ToUpper(s) {
+	case "L":
+		return Low, nil
+	case "M":
+		return Medium, nil
+	case "Q":
+		return Quartile, nil
+	case "H":
+		return High, nil
+	default:
+		return Low, fmt.Errorf("qrcode: unknown error correction level %q", s)
+	}
+}
+
+// String returns the level name: L, M, Q or H
+func (l Level) String() string {
+	switch l {
+	case Low:
+		return "L"
+	case Medium:
+		return "M"
+	case Quartile:
+		return "Q"
+	case High:
+		return "H"
+	default:
+		return fmt.Sprintf("Level(%d)", int(l))
+	}
+}
+
+// Code is an encoded QR code symbol
+type Code struct {
+	Version int   // Symbol version, 1-40
+	Level   Level // Error correction level
+	Mask    int   // Data mask pattern, 0-7
+	Size    int   // Width and height in modules, excluding the quiet zone
+
+	modules []bool // Row-major, true for dark modules
+}
+
+// Dark reports whether the module at column x and row y is dark
+// Coordinates outside the symbol are light, as in the quiet zone
+func (c *Code) Dark(x, y int) bool {
+	if x < 0 || y < 0 || x >= c.Size || y >= c.Size {
+		return false
+	}
+	return c.modules[y*c.Size+x]
+}
+
+// Encode encodes text in byte mode using the smallest version that fits at the given level
+func Encode(text string, level Level) (*Code, error) {
+	return EncodeBytes([]byte(text), level)
+}
+
+// EncodeBytes encodes binary data in byte mode using the smallest version that fits
+func EncodeBytes(data []byte, level Level) (*Code, error) {
+	if level < Low || level > High {
+		return nil, fmt.Errorf("qrcode: invalid error correction level %d", level)
+	}
+
+	version := 0
+	for v := minVersion; v <= maxVersion; v++ {
+		if byteModeBits(v, len(data)) <= numDataCodewords(v, level)*8 {
+			version = v
+			break
+		}
+	}
+	if version == 0 {
+		return nil, ErrTooLong
+	}
+
+	codewords := addErrorCorrection(dataCodewords(data, version, level), version, level)
+
+	m := newMatrix(version)
+	m.drawFunctionPatterns()
+	m.drawCodewords(codewords)
+	mask := m.applyBestMask(level)
+
+	return &Code{
+		Version: version,
+		Level:   level,
+		Mask:    mask,
+		Size:    m.size,
+		modules: m.modules,
+	}, nil
+}
+
+// byteModeBits returns the bits needed to encode n bytes in byte mode
+func byteModeBits(version, n int) int {
+	return 4 + charCountBits(version) + 8*n
+}
+
+// charCountBits returns the length of the byte mode character count indicator
+func charCountBits(version int) int {
+	if version <= 9 {
+		return 8
+	}
+	return 16
+}
+
+// dataCodewords builds the padded data codeword sequence for a byte mode segment
+func dataCodewords(data []byte, version int, level Level) []byte {
+	capacity := numDataCodewords(version, level) * 8
+
+	var bits bitBuffer
+	bits.append(0b0100, 4) // Byte mode indicator
+	bits.append(len(data), charCountBits(version))
+	for _, b := range data {
+		bits.append(int(b), 8)
+	}
+
+	// Terminator of up to four zero bits, then pad to a byte boundary
+	terminator := capacity - bits.len()
+	if terminator > 4 {
+		terminator = 4
+	}
+	bits.append(0, terminator)
+	if rem := bits.len() % 8; rem != 0 {
+		bits.append(0, 8-rem)
+	}
+
+	// Fill the remaining capacity with alternating pad codewords
+	for pad := 0xEC; bits.len() < capacity; pad ^= 0xEC ^ 0x11 {
+		bits.append(pad, 8)
+	}
+
+	return bits.bytes()
+}
+
+// addErrorCorrection splits data into blocks, appends Reed-Solomon codewords to
+// each block and interleaves the result
+func addErrorCorrection(data []byte, version int, level Level) []byte {
+	numBlocks := numErrorCorrectionBlocks[level][version]
+	blockEccLen := eccCodewordsPerBlock[level][version]
+	rawCodewords := numRawDataModules(version) / 8
+	numShortBlocks := numBlocks - rawCodewords%numBlocks
+	shortBlockLen := rawCodewords / numBlocks
+
+	divisor := reedSolomonDivisor(blockEccLen)
+	blocks := make([][]byte, 0, numBlocks)
+	for i, k := 0, 0; i < numBlocks; i++ {
+		dataLen := shortBlockLen - blockEccLen
+		if i >= numShortBlocks {
+			dataLen++
+		}
+
+		block := make([]byte, 0, shortBlockLen+1)
+		block = append(block, data[k:k+dataLen]...)
+		k += dataLen
+		ecc := reedSolomonRemainder(block, divisor)
+
+		// Short blocks get a placeholder so all blocks have equal length while interleaving
+		if i < numShortBlocks {
+			block = append(block, 0)
+		}
+		blocks = append(blocks, append(block, ecc...))
+	}
+
+	result := make([]byte, 0, rawCodewords)
+	for i := range blocks[0] {
+		for j, block := range blocks {
+			if i != shortBlockLen-blockEccLen || j >= numShortBlocks {
+				result = append(result, block[i])
+			}
+		}
+	}
+	return result
+}
+
+// reedSolomonDivisor returns the generator polynomial of the given degree,
+// highest coefficient first with the leading 1 omitted
+func reedSolomonDivisor(degree int) []byte {
+	result := make([]byte, degree)
+	result[degree-1] = 1
+
+	root := byte(1)
+	for i := 0; i < degree; i++ {
+		for j := range result {
+			result[j] = gfMultiply(result[j], root)
+			if j+1 < len(result) {
+				result[j] ^= result[j+1]
+			}
+		}
+		root = gfMultiply(root, 0x02)
+	}
+	return result
+}
+
+// reedSolomonRemainder returns the error correction codewords of data
+func reedSolomonRemainder(data, divisor []byte) []byte {
+	result := make([]byte, len(divisor))
+	for _, b := range data {
+		factor := b ^ result[0]
+		copy(result, result[1:])
+		result[len(result)-1] = 0
+		for i, coef := range divisor {
+			result[i] ^= gfMultiply(coef, factor)
+		}
+	}
+	return result
+}
+
+// gfMultiply multiplies two elements of GF(2^8) modulo x^8 + x^4 + x^3 + x^2 + 1
+func gfMultiply(x, y byte) byte {
+	z := 0
+	for i := 7; i >= 0; i-- {
+		z = (z << 1) ^ ((z >> 7) * 0x11D)
+		z ^= int((y>>i)&1) * int(x)
+	}
+	return byte(z)
+}
+
+// bitBuffer is an append-only sequence of bits
+type bitBuffer struct {
+	bits []bool
+}
+
+// append appends the low n bits of value, most significant first
+func (b *bitBuffer) append(value, n int) {
+	for i := n - 1; i >= 0; i-- {
+		b.bits = append(b.bits, (value>>i)&1 != 0)
+	}
+}
+
+// len returns the number of bits in the buffer
+func (b *bitBuffer) len() int {
+	return len(b.bits)
+}
+
+// bytes packs the buffer into bytes; its length must be a multiple of 8
+func (b *bitBuffer) bytes() []byte {
+	result := make([]byte, len(b.bits)/8)
+	for i, bit := range b.bits {
+		if bit {
+			result[i/8] |= 1 << (7 - i%8)
+		}
+	}
+	return result
+}
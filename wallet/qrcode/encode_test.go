@@ -0,0 +1,458 @@
+package qrcode
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// rows renders a code as one string per row, '#' for dark and '.' for light modules
+func rows(c *Code) []string {
+	out := make([]string, c.Size)
+	for y := 0; y < c.Size; y++ {
+		var b strings.Builder
+		for x := 0; x < c.Size; x++ {
+			if c.Dark(x, y) {
+				b.WriteByte('#')
+			} else {
+				b.WriteByte('.')
+			}
+		}
+		out[y] = b.String()
+	}
+	return out
+}
+
+// The reference matrices below were produced by an independent encoder
+// (github.com/skip2/go-qrcode) in byte mode without a quiet zone
+func TestEncodeKnownAnswers(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		level   Level
+		version int
+		mask    int
+		want    []string // Full matrix, for small symbols
+		sha256  string   // SHA-256 of the rows joined by newlines, for large symbols
+	}{
+		{
+			name: "version 1", text: "a", level: Medium, version: 1, mask: 5,
+			want: []string{
+				"#######..#.##.#######",
+				"#.....#.#.##..#.....#",
+				"#.###.#.##.#..#.###.#",
+				"#.###.#.#.##..#.###.#",
+				"#.###.#..#..#.#.###.#",
+				"#.....#...##..#.....#",
+				"#######.#.#.#.#######",
+				"........##...........",
+				"#.....#.#.##.##..###.",
+				"#..##......###.###..#",
+				"..#.###..##.#.##.....",
+				".#.#.#.##..#####.#.#.",
+				"##.#..####.##########",
+				"........##..#.....#.#",
+				"#######..###.#..####.",
+				"#.....#...#...#...###",
+				"#.###.#..###.#..###..",
+				"#.###.#..#.#####.#...",
+				"#.###.#..#.###.###.##",
+				"#.....#...######.#...",
+				"#######.#.#.#..#..##.",
+			},
+		},
+		{
+			name: "version 4 link", text: "https://a.swallet.link/atw/v3/3hdpejr6qi380#Clip?pdata=ticket-001", level: Low, version: 4, mask: 2,
+			want: []string{
+				"#######...#.#...#.##.###..#######",
+				"#.....#.#.#...#####...#...#.....#",
+				"#.###.#.....#.#......#.#..#.###.#",
+				"#.###.#.###.###.....#..##.#.###.#",
+				"#.###.#..#....#.#.##.####.#.###.#",
+				"#.....#.##.##.#####.......#.....#",
+				"#######.#.#.#.#.#.#.#.#.#.#######",
+				".........###.........###.........",
+				"#####.####.#.#.#.#.##....#.#.#.#.",
+				"..#.#..##.#.##..####...#..#...###",
+				"###..##...#...###...#.#.##.#.#.#.",
+				"..####......#.##..##.##..#..#.#..",
+				"..#.####.##.##...#..#....#.###...",
+				"#.###..#......#.####.###.##....##",
+				"##.######.###..#..#.#....#.#...#.",
+				"#####......#...##....##.#...#.#..",
+				"..#######..#.#.###.#..#.#..##..#.",
+				"#.###...#.#.#.#.#..##..#..#..#.##",
+				"#.#####.#.#....##.#.##...##..#.#.",
+				"...###.#....#..##.#..#.#..##..#..",
+				"...##.#...#.##..###.#.####.##..#.",
+				"##.#...#..#...#..#.###.##.#..#.##",
+				"#.##.##..#.##..###...##..##.##.#.",
+				"#.......##.#..##...###.##....##..",
+				"#...####.###...#.#..#.#######...#",
+				"........#...##..#..#.##.#...#.#.#",
+				"#######.##...###..#..#.##.#.####.",
+				"#.....#..#..##.##.##.#.##...#.##.",
+				"#.###.#.###.#.####..#.#.#####....",
+				"#.###.#.##......#.####...#..##...",
+				"#.###.#.#####..##....###..#....#.",
+				"#.....#.####..##..##.##...#.###..",
+				"#######.#.##...#.#.#..####.#.#.#.",
+			},
+		},
+		{
+			name: "version 11", text: strings.Repeat("samsung wallet ", 20), level: Low, version: 11, mask: 2,
+			sha256: "c8bce467c4744080f802ac9e27b71cf7ba359798237d2c503ed9fb1120d86bec",
+		},
+		{
+			name: "version 13", text: strings.Repeat("x", 300), level: Medium, version: 13, mask: 0,
+			sha256: "2abaf9ed149140d46fff8f03cf322665362cc2e1bb0134fd0862de0f78f0c5c8",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, err := Encode(tt.text, tt.level)
+			if err != nil {
+				t.Fatalf("Encode() error = %v", err)
+			}
+			if code.Version != tt.version || code.Mask != tt.mask {
+				t.Errorf("Encode() version %d mask %d, want version %d mask %d", code.Version, code.Mask, tt.version, tt.mask)
+			}
+
+			got := rows(code)
+			if tt.want != nil {
+				if len(got) != len(tt.want) {
+					t.Fatalf("Encode() size %d, want %d", len(got), len(tt.want))
+				}
+				for y := range tt.want {
+					if got[y] != tt.want[y] {
+						t.Errorf("row %d = %s, want %s", y, got[y], tt.want[y])
+					}
+				}
+				return
+			}
+			sum := sha256.Sum256([]byte(strings.Join(got, "\n")))
+			if hex.EncodeToString(sum[:]) != tt.sha256 {
+				t.Errorf("Encode() matrix SHA-256 = %x, want %s", sum, tt.sha256)
+			}
+		})
+	}
+}
+
+func TestReedSolomonRemainder(t *testing.T) {
+	// "HELLO WORLD" as version 1-M in alphanumeric mode, a widely published example
+	data := []byte{0x20, 0x5B, 0x0B, 0x78, 0xD1, 0x72, 0xDC, 0x4D, 0x43, 0x40, 0xEC, 0x11, 0xEC, 0x11, 0xEC, 0x11}
+	want := []byte{0xC4, 0x23, 0x27, 0x77, 0xEB, 0xD7, 0xE7, 0xE2, 0x5D, 0x17}
+
+	got := reedSolomonRemainder(data, reedSolomonDivisor(len(want)))
+	if !bytes.Equal(got, want) {
+		t.Errorf("reedSolomonRemainder() = % X, want % X", got, want)
+	}
+}
+
+func TestEncodeRoundTrip(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for _, level := range []Level{Low, Medium, Quartile, High} {
+		for _, n := range []int{0, 1, 2, 17, 32, 100, 271, 500, 1000, 1273} {
+			data := make([]byte, n)
+			rng.Read(data)
+
+			code, err := EncodeBytes(data, level)
+			if err != nil {
+				t.Fatalf("EncodeBytes(%d bytes, %v) error = %v", n, level, err)
+			}
+			got, err := decode(code)
+			if err != nil {
+				t.Fatalf("decode(%d bytes, %v, version %d, mask %d) error = %v", n, level, code.Version, code.Mask, err)
+			}
+			if !bytes.Equal(got, data) {
+				t.Fatalf("decode(%d bytes, %v) round trip mismatch", n, level)
+			}
+		}
+	}
+}
+
+func TestEncodeCapacity(t *testing.T) {
+	tests := []struct {
+		level Level
+		max   int // Byte mode capacity of version 40
+	}{
+		{Low, 2953},
+		{Medium, 2331},
+		{Quartile, 1663},
+		{High, 1273},
+	}
+	for _, tt := range tests {
+		code, err := EncodeBytes(make([]byte, tt.max), tt.level)
+		if err != nil || code.Version != 40 {
+			t.Errorf("EncodeBytes(%d bytes, %v) = version %v, %v; want version 40", tt.max, tt.level, code, err)
+		}
+		if _, err := EncodeBytes(make([]byte, tt.max+1), tt.level); !errors.Is(err, ErrTooLong) {
+			t.Errorf("EncodeBytes(%d bytes, %v) error = %v, want ErrTooLong", tt.max+1, tt.level, err)
+		}
+	}
+
+	if _, err := Encode("x", Level(4)); err == nil {
+		t.Error("Encode() with invalid level succeeded")
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	for _, s := range []string{"L", "m", "Q", "h"} {
+		level, err := ParseLevel(s)
+		if err != nil || level.String() != strings.ToUpper(s) {
+			t.Errorf("ParseLevel(%q) = %v, %v", s, level, err)
+		}
+	}
+	if _, err := ParseLevel("X"); err == nil {
+		t.Error("ParseLevel(X) succeeded")
+	}
+}
+
+// decode reads the data back from a code the way a scanner would once the symbol
+// is located, written from the standard independently of the encoder
+func decode(c *Code) ([]byte, error) {
+	size := c.Size
+	version := (size - 17) / 4
+
+	level, mask, err := readFormat(c)
+	if err != nil {
+		return nil, err
+	}
+	if mask != c.Mask || level != c.Level {
+		return nil, errors.New("format information does not match the code")
+	}
+
+	// Read the data modules in zigzag order, undoing the mask
+	reserved := functionModules(version)
+	var bits []bool
+	for right := size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		upward := (right+1)&2 == 0
+		for vert := 0; vert < size; vert++ {
+			y := vert
+			if upward {
+				y = size - 1 - vert
+			}
+			for j := 0; j < 2; j++ {
+				x := right - j
+				if reserved[y*size+x] {
+					continue
+				}
+				bits = append(bits, c.Dark(x, y) != masked(mask, x, y))
+			}
+		}
+	}
+
+	codewords := make([]byte, len(bits)/8)
+	for i := range codewords {
+		for j := 0; j < 8; j++ {
+			if bits[i*8+j] {
+				codewords[i] |= 0x80 >> j
+			}
+		}
+	}
+
+	// De-interleave the blocks and check each against its error correction codewords
+	numBlocks := numErrorCorrectionBlocks[level][version]
+	eccLen := eccCodewordsPerBlock[level][version]
+	shortBlocks := numBlocks - len(codewords)%numBlocks
+	shortDataLen := len(codewords)/numBlocks - eccLen
+
+	blocks := make([][]byte, numBlocks)
+	next := 0
+	for i := 0; i <= shortDataLen; i++ {
+		for b := range blocks {
+			if i < shortDataLen || b >= shortBlocks {
+				blocks[b] = append(blocks[b], codewords[next])
+				next++
+			}
+		}
+	}
+	for i := 0; i < eccLen; i++ {
+		for b := range blocks {
+			blocks[b] = append(blocks[b], codewords[next])
+			next++
+		}
+	}
+
+	var data []byte
+	for b, block := range blocks {
+		for k := 0; k < eccLen; k++ {
+			if syndrome(block, k) != 0 {
+				return nil, errors.New("error correction check failed")
+			}
+		}
+		data = append(data, blocks[b][:len(block)-eccLen]...)
+	}
+
+	// Parse the byte mode segment
+	reader := bitReader{data: data}
+	if mode := reader.read(4); mode != 0x4 {
+		return nil, errors.New("not a byte mode segment")
+	}
+	countBits := 8
+	if version > 9 {
+		countBits = 16
+	}
+	out := make([]byte, reader.read(countBits))
+	for i := range out {
+		out[i] = byte(reader.read(8))
+	}
+	return out, nil
+}
+
+// readFormat reads the format information around the top left finder pattern
+func readFormat(c *Code) (Level, int, error) {
+	var bits int
+	for i := 0; i <= 5; i++ {
+		bits |= boolBit(c.Dark(8, i)) << i
+	}
+	bits |= boolBit(c.Dark(8, 7)) << 6
+	bits |= boolBit(c.Dark(8, 8)) << 7
+	bits |= boolBit(c.Dark(7, 8)) << 8
+	for i := 9; i < 15; i++ {
+		bits |= boolBit(c.Dark(14-i, 8)) << i
+	}
+
+	// Format level indicators: L=01, M=00, Q=11, H=10
+	indicators := map[Level]int{Low: 1, Medium: 0, Quartile: 3, High: 2}
+	for level, indicator := range indicators {
+		for mask := 0; mask < 8; mask++ {
+			value := indicator<<3 | mask
+			rem := value
+			for i := 0; i < 10; i++ {
+				rem = rem<<1 ^ (rem>>9)*0x537
+			}
+			if (value<<10|rem)^0x5412 == bits {
+				return level, mask, nil
+			}
+		}
+	}
+	return 0, 0, errors.New("invalid format information")
+}
+
+// functionModules marks the modules that do not hold data
+func functionModules(version int) []bool {
+	size := version*4 + 17
+	reserved := make([]bool, size*size)
+	fill := func(x0, y0, x1, y1 int) {
+		for y := y0; y <= y1; y++ {
+			for x := x0; x <= x1; x++ {
+				reserved[y*size+x] = true
+			}
+		}
+	}
+
+	// Finder patterns, separators and format information
+	fill(0, 0, 8, 8)
+	fill(size-8, 0, size-1, 8)
+	fill(0, size-8, 8, size-1)
+
+	// Timing patterns
+	fill(6, 0, 6, size-1)
+	fill(0, 6, size-1, 6)
+
+	// Alignment patterns
+	positions := alignmentPatternPositions(version)
+	last := len(positions) - 1
+	for i, y := range positions {
+		for j, x := range positions {
+			if (i == 0 && j == 0) || (i == 0 && j == last) || (i == last && j == 0) {
+				continue
+			}
+			fill(x-2, y-2, x+2, y+2)
+		}
+	}
+
+	// Version information
+	if version >= 7 {
+		fill(size-11, 0, size-9, 5)
+		fill(0, size-11, 5, size-9)
+	}
+	return reserved
+}
+
+// masked reports whether a data mask pattern inverts the module at column x and row y
+func masked(mask, x, y int) bool {
+	i, j := y, x
+	switch mask {
+	case 0:
+		return (i+j)%2 == 0
+	case 1:
+		return i%2 == 0
+	case 2:
+		return j%3 == 0
+	case 3:
+		return (i+j)%3 == 0
+	case 4:
+		return (i/2+j/3)%2 == 0
+	case 5:
+		return i*j%2+i*j%3 == 0
+	case 6:
+		return (i*j%2+i*j%3)%2 == 0
+	default:
+		return ((i+j)%2+i*j%3)%2 == 0
+	}
+}
+
+// syndrome evaluates a block polynomial at alpha^k in GF(256) with polynomial 0x11D
+func syndrome(block []byte, k int) byte {
+	x := byte(1)
+	for i := 0; i < k; i++ {
+		x = gfMul(x, 2)
+	}
+	var result byte
+	for _, b := range block {
+		result = gfMul(result, x) ^ b
+	}
+	return result
+}
+
+// gfMul multiplies in GF(256) by shift and add
+func gfMul(a, b byte) byte {
+	var result byte
+	for b > 0 {
+		if b&1 != 0 {
+			result ^= a
+		}
+		carry := a & 0x80
+		a <<= 1
+		if carry != 0 {
+			a ^= 0x1D
+		}
+		b >>= 1
+	}
+	return result
+}
+
+// bitReader reads big-endian bit fields
+type bitReader struct {
+	data []byte
+	pos  int
+}
+
+// read returns the next n bits
+func (r *bitReader) read(n int) int {
+	value := 0
+	for i := 0; i < n; i++ {
+		bit := int(r.data[r.pos/8]>>(7-r.pos%8)) & 1
+		value = value<<1 | bit
+		r.pos++
+	}
+	return value
+}
+
+// boolBit converts a module to a bit
+func boolBit(dark bool) int {
+	if dark {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,333 @@
+package qrcode
+
+// matrix is the module grid of a symbol under construction
+type matrix struct {
+	version    int
+	size       int
+	modules    []bool // Row-major, true for dark modules
+	isFunction []bool // Modules reserved for function patterns and format/version information
+}
+
+// newMatrix returns an empty matrix for a version
+func newMatrix(version int) *matrix {
+	size := version*4 + 17
+	return &matrix{
+		version:    version,
+		size:       size,
+		modules:    make([]bool, size*size),
+		isFunction: make([]bool, size*size),
+	}
+}
+
+// get returns the module at column x and row y
+func (m *matrix) get(x, y int) bool {
+	return m.modules[y*m.size+x]
+}
+
+// setFunction sets a module and marks it as part of a function pattern
+func (m *matrix) setFunction(x, y int, dark bool) {
+	m.modules[y*m.size+x] = dark
+	m.isFunction[y*m.size+x] = true
+}
+
+// drawFunctionPatterns draws finder, timing and alignment patterns and reserves
+// the format and version information areas
+func (m *matrix) drawFunctionPatterns() {
+	// Timing patterns
+	for i := 0; i < m.size; i++ {
+		m.setFunction(6, i, i%2 == 0)
+		m.setFunction(i, 6, i%2 == 0)
+	}
+
+	// Finder patterns with their separators
+	m.drawFinderPattern(3, 3)
+	m.drawFinderPattern(m.size-4, 3)
+	m.drawFinderPattern(3, m.size-4)
+
+	// Alignment patterns, except where they would overlap the finder patterns
+	positions := alignmentPatternPositions(m.version)
+	last := len(positions) - 1
+	for i, y := range positions {
+		for j, x := range positions {
+			if (i == 0 && j == 0) || (i == 0 && j == last) || (i == last && j == 0) {
+				continue
+			}
+			m.drawAlignmentPattern(x, y)
+		}
+	}
+
+	// Reserve format information with a dummy mask; it is redrawn once the mask is chosen
+	m.drawFormatBits(Low, 0)
+	m.drawVersion()
+}
+
+// drawFinderPattern draws a finder pattern and separator centered at x, y
+func (m *matrix) drawFinderPattern(x, y int) {
+	for dy := -4; dy <= 4; dy++ {
+		for dx := -4; dx <= 4; dx++ {
+			xx, yy := x+dx, y+dy
+			if xx < 0 || xx >= m.size || yy < 0 || yy >= m.size {
+				continue
+			}
+			dist := max(abs(dx), abs(dy))
+			m.setFunction(xx, yy, dist != 2 && dist != 4)
+		}
+	}
+}
+
+// drawAlignmentPattern draws an alignment pattern centered at x, y
+func (m *matrix) drawAlignmentPattern(x, y int) {
+	for dy := -2; dy <= 2; dy++ {
+		for dx := -2; dx <= 2; dx++ {
+			m.setFunction(x+dx, y+dy, max(abs(dx), abs(dy)) != 1)
+		}
+	}
+}
+
+// drawFormatBits draws both copies of the format information for a level and mask
+func (m *matrix) drawFormatBits(level Level, mask int) {
+	data := formatBits[level]<<3 | mask
+	rem := data
+	for i := 0; i < 10; i++ {
+		rem = (rem << 1) ^ ((rem >> 9) * 0x537)
+	}
+	bits := (data<<10 | rem) ^ 0x5412
+
+	bit := func(i int) bool {
+		return (bits>>i)&1 != 0
+	}
+
+	// First copy, around the top-left finder pattern
+	for i := 0; i <= 5; i++ {
+		m.setFunction(8, i, bit(i))
+	}
+	m.setFunction(8, 7, bit(6))
+	m.setFunction(8, 8, bit(7))
+	m.setFunction(7, 8, bit(8))
+	for i := 9; i < 15; i++ {
+		m.setFunction(14-i, 8, bit(i))
+	}
+
+	// Second copy, split between the top-right and bottom-left finder patterns
+	for i := 0; i < 8; i++ {
+		m.setFunction(m.size-1-i, 8, bit(i))
+	}
+	for i := 8; i < 15; i++ {
+		m.setFunction(8, m.size-15+i, bit(i))
+	}
+
+	// The dark module is always set
+	m.setFunction(8, m.size-8, true)
+}
+
+// drawVersion draws both copies of the version information (versions 7 and up)
+func (m *matrix) drawVersion() {
+	if m.version < 7 {
+		return
+	}
+
+	rem := m.version
+	for i := 0; i < 12; i++ {
+		rem = (rem << 1) ^ ((rem >> 11) * 0x1F25)
+	}
+	bits := m.version<<12 | rem
+
+	for i := 0; i < 18; i++ {
+		dark := (bits>>i)&1 != 0
+		a := m.size - 11 + i%3
+		b := i / 3
+		m.setFunction(a, b, dark)
+		m.setFunction(b, a, dark)
+	}
+}
+
+// drawCodewords places the codewords in the zigzag order defined by the standard
+func (m *matrix) drawCodewords(codewords []byte) {
+	i := 0
+	for right := m.size - 1; right >= 1; right -= 2 {
+		// Skip the vertical timing pattern
+		if right == 6 {
+			right = 5
+		}
+		upward := (right+1)&2 == 0
+		for vert := 0; vert < m.size; vert++ {
+			y := vert
+			if upward {
+				y = m.size - 1 - vert
+			}
+			for j := 0; j < 2; j++ {
+				x := right - j
+				if m.isFunction[y*m.size+x] || i >= len(codewords)*8 {
+					continue
+				}
+				m.modules[y*m.size+x] = (codewords[i>>3]>>(7-i&7))&1 != 0
+				i++
+			}
+		}
+	}
+}
+
+// applyMask XORs the data modules with a mask pattern; applying it twice undoes it
+func (m *matrix) applyMask(mask int) {
+	for y := 0; y < m.size; y++ {
+		for x := 0; x < m.size; x++ {
+			if !m.isFunction[y*m.size+x] && maskBit(mask, x, y) {
+				m.modules[y*m.size+x] = !m.modules[y*m.size+x]
+			}
+		}
+	}
+}
+
+// maskBit reports whether mask pattern inverts the module at x, y
+func maskBit(mask, x, y int) bool {
+	switch mask {
+	case 0:
+		return (x+y)%2 == 0
+	case 1:
+		return y%2 == 0
+	case 2:
+		return x%3 == 0
+	case 3:
+		return (x+y)%3 == 0
+	case 4:
+		return (x/3+y/2)%2 == 0
+	case 5:
+		return x*y%2+x*y%3 == 0
+	case 6:
+		return (x*y%2+x*y%3)%2 == 0
+	default:
+		return ((x+y)%2+x*y%3)%2 == 0
+	}
+}
+
+// applyBestMask applies the mask with the lowest penalty and draws its format information
+func (m *matrix) applyBestMask(level Level) int {
+	best, bestPenalty := 0, -1
+	for mask := 0; mask < 8; mask++ {
+		m.applyMask(mask)
+		m.drawFormatBits(level, mask)
+		if penalty := m.penalty(); bestPenalty < 0 || penalty < bestPenalty {
+			best, bestPenalty = mask, penalty
+		}
+		m.applyMask(mask)
+	}
+
+	m.applyMask(best)
+	m.drawFormatBits(level, best)
+	return best
+}
+
+// Penalty weights from the standard
+const (
+	penaltyN1 = 3
+	penaltyN2 = 3
+	penaltyN3 = 40
+	penaltyN4 = 10
+)
+
+// penalty scores the current module layout; lower is easier to scan
+// The scoring follows Nayuki's reference implementation, which treats the quiet
+// zone around the symbol as light when looking for finder-like patterns
+func (m *matrix) penalty() int {
+	result := 0
+
+	// Runs of five or more same-colored modules and finder-like patterns, in rows and columns
+	for y := 0; y < m.size; y++ {
+		result += m.linePenalty(func(i int) bool { return m.get(i, y) })
+	}
+	for x := 0; x < m.size; x++ {
+		result += m.linePenalty(func(i int) bool { return m.get(x, i) })
+	}
+
+	// 2x2 blocks of the same color
+	for y := 0; y < m.size-1; y++ {
+		for x := 0; x < m.size-1; x++ {
+			c := m.get(x, y)
+			if c == m.get(x+1, y) && c == m.get(x, y+1) && c == m.get(x+1, y+1) {
+				result += penaltyN2
+			}
+		}
+	}
+
+	// Balance of dark and light modules
+	dark := 0
+	for _, module := range m.modules {
+		if module {
+			dark++
+		}
+	}
+	total := m.size * m.size
+	k := (abs(dark*20-total*10)+total-1)/total - 1
+	result += k * penaltyN4
+
+	return result
+}
+
+// linePenalty scores one row or column, read through at
+func (m *matrix) linePenalty(at func(int) bool) int {
+	result := 0
+
+	var history runHistory
+	runDark, run := false, 0
+	for i := 0; i < m.size; i++ {
+		if at(i) == runDark {
+			run++
+			if run == 5 {
+				result += penaltyN1
+			} else if run > 5 {
+				result++
+			}
+			continue
+		}
+		history.add(run, m.size)
+		if !runDark {
+			result += history.finderPatterns() * penaltyN3
+		}
+		runDark, run = at(i), 1
+	}
+
+	// Close the line with the light quiet zone
+	if runDark {
+		history.add(run, m.size)
+		run = 0
+	}
+	history.add(run+m.size, m.size)
+	result += history.finderPatterns() * penaltyN3
+
+	return result
+}
+
+// runHistory holds the lengths of the last seven runs of a line, newest first
+type runHistory [7]int
+
+// add records a finished run; the first run of a line is extended by the light quiet zone
+func (h *runHistory) add(run, size int) {
+	if h[0] == 0 {
+		run += size
+	}
+	copy(h[1:], h[:len(h)-1])
+	h[0] = run
+}
+
+// finderPatterns counts the 1:1:3:1:1 finder-like patterns that end the history,
+// with four light modules before or after them
+func (h *runHistory) finderPatterns() int {
+	n := h[1]
+	core := n > 0 && h[2] == n && h[3] == n*3 && h[4] == n && h[5] == n
+	count := 0
+	if core && h[0] >= n*4 && h[6] >= n {
+		count++
+	}
+	if core && h[6] >= n*4 && h[0] >= n {
+		count++
+	}
+	return count
+}
+
+// abs returns the absolute value of x
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,150 @@
+package qrcode
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+)
+
+const (
+	// DefaultModuleSize is the default width of one module in pixels (PNG) or user units (SVG)
+	DefaultModuleSize = 8
+
+	// DefaultQuietZone is the quiet zone width in modules required by the standard
+	DefaultQuietZone = 4
+)
+
+// renderOptions configures image rendering
+type renderOptions struct {
+	moduleSize int
+	quietZone  int
+	foreground color.Color
+	background color.Color
+}
+
+// RenderOption configures how a Code is rendered
+type RenderOption func(*renderOptions)
+
+// WithModuleSize sets the width of one module
+func WithModuleSize(size int) RenderOption {
+	return func(o *renderOptions) {
+		o.moduleSize = size
+	}
+}
+
+// WithQuietZone sets the quiet zone width in modules; 0 renders without a border
+func WithQuietZone(modules int) RenderOption {
+	return func(o *renderOptions) {
+		o.quietZone = modules
+	}
+}
+
+// WithColors sets the dark and light module colors; nil colors are rejected when rendering
+func WithColors(foreground, background color.Color) RenderOption {
+	return func(o *renderOptions) {
+		o.foreground = foreground
+		o.background = background
+	}
+}
+
+// newRenderOptions applies opts over the defaults
+func newRenderOptions(opts []RenderOption) (renderOptions, error) {
+	options := renderOptions{
+		moduleSize: DefaultModuleSize,
+		quietZone:  DefaultQuietZone,
+		foreground: color.Black,
+		background: color.White,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	if options.moduleSize < 1 {
+		return options, fmt.Errorf("qrcode: module size must be positive, got %d", options.moduleSize)
+	}
+	if options.quietZone < 0 {
+		return options, fmt.Errorf("qrcode: quiet zone must not be negative, got %d", options.quietZone)
+	}
+	if options.foreground == nil || options.background == nil {
+		return options, fmt.Errorf("qrcode: colors must not be nil")
+	}
+	return options, nil
+}
+
+// Image renders the code as a paletted image
+func (c *Code) Image(opts ...RenderOption) (image.Image, error) {
+	options, err := newRenderOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	width := (c.Size + 2*options.quietZone) * options.moduleSize
+	img := image.NewPaletted(image.Rect(0, 0, width, width), color.Palette{options.background, options.foreground})
+	for y := 0; y < width; y++ {
+		for x := 0; x < width; x++ {
+			if c.Dark(x/options.moduleSize-options.quietZone, y/options.moduleSize-options.quietZone) {
+				img.SetColorIndex(x, y, 1)
+			}
+		}
+	}
+	return img, nil
+}
+
+// PNG renders the code as a PNG image
+func (c *Code) PNG(opts ...RenderOption) ([]byte, error) {
+	img, err := c.Image(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("failed to encode PNG: %v", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// SVG renders the code as a standalone SVG document
+// Dark modules are drawn as a single path so the output stays small and scales cleanly
+func (c *Code) SVG(opts ...RenderOption) ([]byte, error) {
+	options, err := newRenderOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	modules := c.Size + 2*options.quietZone
+	width := modules * options.moduleSize
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<?xml version="1.0" encoding="UTF-8"?>`+"\n")
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" version="1.1" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`+"\n",
+		width, width, modules, modules)
+	fmt.Fprintf(&buf, `<rect width="100%%" height="100%%" fill="%s"/>`+"\n", hexColor(options.background))
+
+	buf.WriteString(`<path d="`)
+	first := true
+	for y := 0; y < c.Size; y++ {
+		for x := 0; x < c.Size; x++ {
+			if !c.Dark(x, y) {
+				continue
+			}
+			if !first {
+				buf.WriteByte(' ')
+			}
+			first = false
+			fmt.Fprintf(&buf, "M%d,%dh1v1h-1z", x+options.quietZone, y+options.quietZone)
+		}
+	}
+	fmt.Fprintf(&buf, `" fill="%s"/>`+"\n", hexColor(options.foreground))
+	buf.WriteString("</svg>\n")
+
+	return buf.Bytes(), nil
+}
+
+// hexColor formats a color as #rrggbb, ignoring alpha
+func hexColor(c color.Color) string {
+	r, g, b, _ := c.RGBA()
+	return fmt.Sprintf("#%02x%02x%02x", r>>8, g>>8, b>>8)
+}
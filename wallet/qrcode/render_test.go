@@ -0,0 +1,92 @@
+package qrcode
+
+import (
+	"bytes"
+	"image/color"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+func TestPNG(t *testing.T) {
+	code, err := Encode("https://a.swallet.link", Medium)
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+
+	data, err := code.PNG(WithModuleSize(3), WithQuietZone(2))
+	if err != nil {
+		t.Fatalf("PNG() error = %v", err)
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("png.Decode() error = %v", err)
+	}
+
+	want := (code.Size + 4) * 3
+	if b := img.Bounds(); b.Dx() != want || b.Dy() != want {
+		t.Fatalf("PNG() size = %dx%d, want %dx%d", b.Dx(), b.Dy(), want, want)
+	}
+
+	// Every pixel of a module takes the module's color, and the quiet zone is light
+	for y := 0; y < want; y++ {
+		for x := 0; x < want; x++ {
+			r, _, _, _ := img.At(x, y).RGBA()
+			if dark := code.Dark(x/3-2, y/3-2); dark != (r == 0) {
+				t.Fatalf("pixel (%d, %d) dark = %v, want %v", x, y, r == 0, dark)
+			}
+		}
+	}
+}
+
+func TestSVG(t *testing.T) {
+	code, err := Encode("a", Low)
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+
+	data, err := code.SVG(WithQuietZone(0), WithColors(color.RGBA{0x11, 0x22, 0x33, 0xFF}, color.White))
+	if err != nil {
+		t.Fatalf("SVG() error = %v", err)
+	}
+	svg := string(data)
+
+	dark := 0
+	for y := 0; y < code.Size; y++ {
+		for x := 0; x < code.Size; x++ {
+			if code.Dark(x, y) {
+				dark++
+			}
+		}
+	}
+	for _, want := range []string{`viewBox="0 0 21 21"`, `width="168"`, `fill="#112233"`, `fill="#ffffff"`, "M0,0h1v1h-1z"} {
+		if !strings.Contains(svg, want) {
+			t.Errorf("SVG() does not contain %s", want)
+		}
+	}
+	if got := strings.Count(svg, "h1v1h-1z"); got != dark {
+		t.Errorf("SVG() draws %d modules, want %d", got, dark)
+	}
+}
+
+func TestRenderOptionErrors(t *testing.T) {
+	code, err := Encode("a", Low)
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+
+	tests := map[string]RenderOption{
+		"zero module size":    WithModuleSize(0),
+		"negative quiet zone": WithQuietZone(-1),
+		"nil foreground":      WithColors(nil, color.White),
+		"nil background":      WithColors(color.Black, nil),
+	}
+	for name, opt := range tests {
+		if _, err := code.PNG(opt); err == nil {
+			t.Errorf("PNG() with %s succeeded", name)
+		}
+		if _, err := code.SVG(opt); err == nil {
+			t.Errorf("SVG() with %s succeeded", name)
+		}
+	}
+}
@@ -0,0 +1,394 @@
+package wallet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultRedirectPrefix is the path the redirect handler is mounted under
+const defaultRedirectPrefix = "/wallet/add/"
+
+var (
+	// ErrTokenNotFound is returned by a CardStore when a redirect token is unknown
+	ErrTokenNotFound = errors.New("redirect token not found")
+
+	// ErrTokenUsed is returned by a ConsumingCardStore when a one-time token was already redeemed
+	ErrTokenUsed = errors.New("redirect token already used")
+)
+
+// StoredCard is a card kept behind a redirect token
+type StoredCard struct {
+	CardID      string     // Card ID from Partners Portal
+	Card        WalletCard // Card to add
+	CallbackURL string     // Optional callback URL of the link
+	Referrer    string     // Optional referrer code of the link
+}
+
+// CardStore resolves redirect tokens to stored cards
+type CardStore interface {
+	// LookupCard returns the card of a token, or ErrTokenNotFound
+	LookupCard(ctx context.Context, token string) (*StoredCard, error)
+}
+
+// ConsumingCardStore is a CardStore that can atomically redeem one-time tokens
+// One-time redirect handlers require it; a store backed by a shared database keeps
+// tokens single-use across multiple server instances
+type ConsumingCardStore interface {
+	CardStore
+
+	// ConsumeCard returns the card of a token and marks the token used, or returns
+	// ErrTokenNotFound or ErrTokenUsed
+	ConsumeCard(ctx context.Context, token string) (*StoredCard, error)
+}
+
+// MemoryCardStore is an in-memory ConsumingCardStore for tests and single-instance deployments
+type MemoryCardStore struct {
+	mu    sync.Mutex
+	cards map[string]*StoredCard
+	used  map[string]bool
+}
+
+// NewMemoryCardStore creates an empty in-memory card store
+func NewMemoryCardStore() *MemoryCardStore {
+	return &MemoryCardStore{
+		cards: make(map[string]*StoredCard),
+		used:  make(map[string]bool),
+	}
+}
+
+// Put stores a card under a token, resetting its used state
+func (s *MemoryCardStore) Put(token string, card *StoredCard) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cards[token] = card
+	delete(s.used, token)
+}
+
+// Delete removes a token
+func (s *MemoryCardStore) Delete(token string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.cards, token)
+	delete(s.used, token)
+}
+
+// LookupCard returns the card of a token
+func (s *MemoryCardStore) LookupCard(ctx context.Context, token string) (*StoredCard, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	card, ok := s.cards[token]
+	if !ok {
+		return nil, ErrTokenNotFound
+	}
+	return card, nil
+}
+
+// ConsumeCard returns the card of a token and marks the token used
+func (s *MemoryCardStore) ConsumeCard(ctx context.Context, token string) (*StoredCard, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	card, ok := s.cards[token]
+	if !ok {
+		return nil, ErrTokenNotFound
+	}
+	if s.used[token] {
+		return nil, ErrTokenUsed
+	}
+	s.used[token] = true
+	return card, nil
+}
+
+// RedirectOptions configures a RedirectHandler
+type RedirectOptions struct {
+	PathPrefix  string                           // Path prefix before the token when not mounted with a {token} pattern (default "/wallet/add/")
+	OneTimeUse  bool                             // Redeem each token only once; the store must be a ConsumingCardStore
+	ConfirmStep bool                             // Serve a confirm page on GET and redirect on POST
+	RateLimit   int                              // Requests allowed per client per RatePeriod; 0 disables rate limiting
+	RatePeriod  time.Duration                    // Rate limit period (default 1 minute)
+	ClientKey   func(r *http.Request) string     // Identifies the client for rate limiting (default: remote IP)
+	LinkOptions []LinkOption                     // Options applied to every link
+	OnError     func(r *http.Request, err error) // Called for failed redirects, e.g. for logging
+}
+
+// RedirectOption configures RedirectOptions
+type RedirectOption func(*RedirectOptions)
+
+// WithPathPrefix sets the path prefix before the token
+func WithPathPrefix(prefix string) RedirectOption {
+	return func(o *RedirectOptions) {
+		o.PathPrefix = prefix
+	}
+}
+
+// WithOneTimeUse makes each token redeemable only once
+// The handler's store must implement ConsumingCardStore
+func WithOneTimeUse() RedirectOption {
+	return func(o *RedirectOptions) {
+		o.OneTimeUse = true
+	}
+}
+
+// WithConfirmStep serves a page with an "Add to Samsung Wallet" button on GET and
+// redirects only when it is submitted
+// Link scanners in email clients and chat unfurl bots follow links with GET, so
+// without it they can redeem one-time tokens before the user clicks
+func WithConfirmStep() RedirectOption {
+	return func(o *RedirectOptions) {
+		o.ConfirmStep = true
+	}
+}
+
+// WithRateLimit allows at most requests per client in each period
+func WithRateLimit(requests int, period time.Duration) RedirectOption {
+	return func(o *RedirectOptions) {
+		o.RateLimit = requests
+		o.RatePeriod = period
+	}
+}
+
+// WithClientKey sets how clients are identified for rate limiting,
+// e.g. from X-Forwarded-For behind a trusted proxy
+func WithClientKey(clientKey func(r *http.Request) string) RedirectOption {
+	return func(o *RedirectOptions) {
+		o.ClientKey = clientKey
+	}
+}
+
+// WithRedirectLinkOptions sets options applied to every link
+func WithRedirectLinkOptions(opts ...LinkOption) RedirectOption {
+	return func(o *RedirectOptions) {
+		o.LinkOptions = append(o.LinkOptions, opts...)
+	}
+}
+
+// WithRedirectErrorHandler sets a function called for failed redirects
+func WithRedirectErrorHandler(onError func(r *http.Request, err error)) RedirectOption {
+	return func(o *RedirectOptions) {
+		o.OnError = onError
+	}
+}
+
+// RedirectHandler serves stable URLs that mint fresh CDATA and redirect to an ATW link
+// Data transmit CDATA expires 30 seconds after creation, so links sent by email or
+// SMS point here instead and the CDATA is created when the user clicks
+// Any GET redeems a one-time token, including those of link scanners and unfurl
+// bots; use WithConfirmStep where links may be prefetched
+type RedirectHandler struct {
+	client  *Client
+	store   CardStore
+	options RedirectOptions
+	limiter *rateLimiter
+}
+
+// NewRedirectHandler creates a handler that redirects /wallet/add/{token} to a fresh ATW link
+func (c *Client) NewRedirectHandler(store CardStore, opts ...RedirectOption) *RedirectHandler {
+	options := RedirectOptions{
+		PathPrefix: defaultRedirectPrefix,
+		RatePeriod: time.Minute,
+		ClientKey:  remoteIP,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if options.RatePeriod <= 0 {
+		options.RatePeriod = time.Minute
+	}
+	if options.ClientKey == nil {
+		options.ClientKey = remoteIP
+	}
+
+	h := &RedirectHandler{
+		client:  c,
+		store:   store,
+		options: options,
+	}
+	if options.RateLimit > 0 {
+		h.limiter = newRateLimiter(options.RateLimit, options.RatePeriod)
+	}
+	return h
+}
+
+// ServeHTTP implements http.Handler
+func (h *RedirectHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	allowed := r.Method == http.MethodGet || (h.options.ConfirmStep && r.Method == http.MethodPost)
+	if !allowed {
+		if h.options.ConfirmStep {
+			w.Header().Set("Allow", "GET, POST")
+		} else {
+			w.Header().Set("Allow", http.MethodGet)
+		}
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Redirects carry single-use CDATA and must never be cached
+	w.Header().Set("Cache-Control", "no-store")
+
+	if h.limiter != nil {
+		if wait, ok := h.limiter.allow(h.options.ClientKey(r), time.Now()); !ok {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			http.Error(w, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+			return
+		}
+	}
+
+	token := r.PathValue("token")
+	if token == "" {
+		token = strings.TrimPrefix(r.URL.Path, h.options.PathPrefix)
+	}
+	if token == "" || strings.Contains(token, "/") {
+		http.NotFound(w, r)
+		return
+	}
+
+	stored, err := h.store.LookupCard(r.Context(), token)
+	if err != nil {
+		h.fail(w, r, err)
+		return
+	}
+	if h.options.ConfirmStep && r.Method == http.MethodGet {
+		h.confirm(w)
+		return
+	}
+
+	opts := append([]LinkOption{}, h.options.LinkOptions...)
+	if stored.CallbackURL != "" {
+		opts = append(opts, WithCallbackURL(stored.CallbackURL))
+	}
+	if stored.Referrer != "" {
+		opts = append(opts, WithReferrer(stored.Referrer))
+	}
+
+	link, err := h.client.CreateLink(stored.CardID, stored.Card, opts...)
+	if err != nil {
+		h.fail(w, r, fmt.Errorf("failed to create link: %v", err))
+		return
+	}
+
+	// Redeem one-time tokens only once the link exists, so a failed request leaves the token usable
+	if h.options.OneTimeUse {
+		if err := h.consume(r.Context(), token); err != nil {
+			h.fail(w, r, err)
+			return
+		}
+	}
+
+	status := http.StatusFound
+	if r.Method == http.MethodPost {
+		status = http.StatusSeeOther
+	}
+	http.Redirect(w, r, link.URL, status)
+}
+
+// consume redeems a one-time token
+func (h *RedirectHandler) consume(ctx context.Context, token string) error {
+	consumer, ok := h.store.(ConsumingCardStore)
+	if !ok {
+		return fmt.Errorf("one-time use requires a ConsumingCardStore, got %T", h.store)
+	}
+	_, err := consumer.ConsumeCard(ctx, token)
+	return err
+}
+
+// confirmPage is the page served by WithConfirmStep; posting it to the same URL redirects
+const confirmPage = `<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><meta name="viewport" content="width=device-width, initial-scale=1"><meta name="robots" content="noindex"><title>Add to Samsung Wallet</title></head>
+<body><form method="post"><button type="submit">Add to Samsung Wallet</button></form></body>
+</html>
+`
+
+// confirm serves the confirm page
+func (h *RedirectHandler) confirm(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = io.WriteString(w, confirmPage)
+}
+
+// fail reports err and writes the matching status
+func (h *RedirectHandler) fail(w http.ResponseWriter, r *http.Request, err error) {
+	if h.options.OnError != nil {
+		h.options.OnError(r, err)
+	}
+
+	switch {
+	case errors.Is(err, ErrTokenNotFound):
+		http.NotFound(w, r)
+	case errors.Is(err, ErrTokenUsed):
+		http.Error(w, "This link has already been used", http.StatusGone)
+	default:
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+	}
+}
+
+// remoteIP returns the IP address of the request's direct peer
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// rateLimiter is a per-client token bucket
+type rateLimiter struct {
+	mu      sync.Mutex
+	limit   float64
+	period  time.Duration
+	buckets map[string]*bucket
+	swept   time.Time
+}
+
+// bucket holds the remaining requests of one client
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// newRateLimiter allows limit requests per period, refilled continuously
+func newRateLimiter(limit int, period time.Duration) *rateLimiter {
+	return &rateLimiter{
+		limit:   float64(limit),
+		period:  period,
+		buckets: make(map[string]*bucket),
+	}
+}
+
+// allow takes a request from key's bucket, returning how long to wait when it is empty
+func (l *rateLimiter) allow(key string, now time.Time) (time.Duration, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// Drop buckets that have refilled completely so idle clients don't accumulate
+	if now.Sub(l.swept) >= l.period {
+		for k, b := range l.buckets {
+			if now.Sub(b.last) >= l.period {
+				delete(l.buckets, k)
+			}
+		}
+		l.swept = now
+	}
+
+	rate := l.limit / l.period.Seconds()
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: l.limit, last: now}
+		l.buckets[key] = b
+	}
+	b.tokens = math.Min(l.limit, b.tokens+now.Sub(b.last).Seconds()*rate)
+	b.last = now
+
+	if b.tokens < 1 {
+		return time.Duration((1 - b.tokens) / rate * float64(time.Second)), false
+	}
+	b.tokens--
+	return 0, true
+}
@@ -0,0 +1,46 @@
+package wallet
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRateLimiter(t *testing.T) {
+	l := newRateLimiter(3, time.Minute)
+	start := time.Unix(1700000000, 0)
+
+	for i := 0; i < 3; i++ {
+		if _, ok := l.allow("a", start); !ok {
+			t.Fatalf("request %d refused", i)
+		}
+	}
+	wait, ok := l.allow("a", start)
+	if ok || wait != 20*time.Second {
+		t.Fatalf("fourth request = (%v, %v), want (20s, false)", wait, ok)
+	}
+
+	// Other clients have their own bucket
+	if _, ok := l.allow("b", start); !ok {
+		t.Error("other client refused")
+	}
+
+	// One request refills every 20 seconds
+	if wait, ok := l.allow("a", start.Add(15*time.Second)); ok || wait != 5*time.Second {
+		t.Errorf("after 15s = (%v, %v), want (5s, false)", wait, ok)
+	}
+	if _, ok := l.allow("a", start.Add(20*time.Second)); !ok {
+		t.Error("after 20s refused")
+	}
+	if _, ok := l.allow("a", start.Add(20*time.Second)); ok {
+		t.Error("second request after 20s allowed")
+	}
+
+	// Idle buckets are swept once they have refilled
+	l.allow("c", start.Add(2*time.Minute))
+	if _, ok := l.buckets["b"]; ok {
+		t.Error("idle bucket was not swept")
+	}
+	if got := l.buckets["c"].tokens; got != 2 {
+		t.Errorf("new bucket tokens = %v, want 2", got)
+	}
+}
@@ -0,0 +1,173 @@
+package wallet_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/abyssparanoia/samsung-wallet-go/wallet"
+	"github.com/abyssparanoia/samsung-wallet-go/wallet/testkeys"
+)
+
+// lookupOnlyStore is a CardStore that can't consume tokens
+type lookupOnlyStore struct {
+	store *wallet.MemoryCardStore
+}
+
+func (s lookupOnlyStore) LookupCard(ctx context.Context, token string) (*wallet.StoredCard, error) {
+	return s.store.LookupCard(ctx, token)
+}
+
+func newRedirectClient(t *testing.T) *wallet.Client {
+	t.Helper()
+	client, err := testkeys.MustGenerate().Client()
+	if err != nil {
+		t.Fatalf("Client() error = %v", err)
+	}
+	return client
+}
+
+func storedCard(cardID string) *wallet.StoredCard {
+	return &wallet.StoredCard{
+		CardID: cardID,
+		Card: wallet.WalletCard{Card: wallet.WalletCardBody{
+			Type: string(wallet.CardTypeEventTicket),
+			Data: []wallet.WalletCardData{{RefID: "ref-1"}},
+		}},
+	}
+}
+
+func serve(handler http.Handler, method, target string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(method, target, nil))
+	return rec
+}
+
+func TestRedirectHandlerOneTimeUse(t *testing.T) {
+	client := newRedirectClient(t)
+	store := wallet.NewMemoryCardStore()
+	store.Put("ok", storedCard("card-1"))
+	store.Put("broken", storedCard(""))
+	handler := client.NewRedirectHandler(store, wallet.WithOneTimeUse())
+
+	rec := serve(handler, http.MethodGet, "/wallet/add/ok")
+	if rec.Code != http.StatusFound {
+		t.Fatalf("first GET status = %d, want %d", rec.Code, http.StatusFound)
+	}
+	if loc := rec.Header().Get("Location"); !strings.Contains(loc, "/atw/") || !strings.Contains(loc, "cdata=") {
+		t.Errorf("Location = %q, want a data transmit link", loc)
+	}
+	if got := rec.Header().Get("Cache-Control"); got != "no-store" {
+		t.Errorf("Cache-Control = %q, want no-store", got)
+	}
+	if rec := serve(handler, http.MethodGet, "/wallet/add/ok"); rec.Code != http.StatusGone {
+		t.Errorf("second GET status = %d, want %d", rec.Code, http.StatusGone)
+	}
+	if rec := serve(handler, http.MethodGet, "/wallet/add/missing"); rec.Code != http.StatusNotFound {
+		t.Errorf("unknown token status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+
+	// A failed link leaves the token usable
+	if rec := serve(handler, http.MethodGet, "/wallet/add/broken"); rec.Code != http.StatusInternalServerError {
+		t.Fatalf("broken card status = %d, want %d", rec.Code, http.StatusInternalServerError)
+	}
+	if _, err := store.ConsumeCard(context.Background(), "broken"); err != nil {
+		t.Errorf("ConsumeCard() after failed redirect error = %v", err)
+	}
+}
+
+func TestRedirectHandlerOneTimeUseRequiresConsumingStore(t *testing.T) {
+	store := wallet.NewMemoryCardStore()
+	store.Put("ok", storedCard("card-1"))
+
+	var reported error
+	handler := newRedirectClient(t).NewRedirectHandler(lookupOnlyStore{store}, wallet.WithOneTimeUse(),
+		wallet.WithRedirectErrorHandler(func(r *http.Request, err error) { reported = err }))
+
+	if rec := serve(handler, http.MethodGet, "/wallet/add/ok"); rec.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusInternalServerError)
+	}
+	if reported == nil || !strings.Contains(reported.Error(), "ConsumingCardStore") {
+		t.Errorf("reported error = %v, want ConsumingCardStore error", reported)
+	}
+}
+
+func TestRedirectHandlerConfirmStep(t *testing.T) {
+	store := wallet.NewMemoryCardStore()
+	store.Put("ok", storedCard("card-1"))
+	handler := newRedirectClient(t).NewRedirectHandler(store, wallet.WithOneTimeUse(), wallet.WithConfirmStep())
+
+	// Prefetching GETs see the confirm page and leave the token unused
+	for i := 0; i < 2; i++ {
+		rec := serve(handler, http.MethodGet, "/wallet/add/ok")
+		if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `<form method="post">`) {
+			t.Fatalf("GET status = %d, body = %q, want confirm page", rec.Code, rec.Body.String())
+		}
+	}
+
+	rec := serve(handler, http.MethodPost, "/wallet/add/ok")
+	if rec.Code != http.StatusSeeOther || !strings.Contains(rec.Header().Get("Location"), "cdata=") {
+		t.Fatalf("POST status = %d, Location = %q, want redirect to a data transmit link", rec.Code, rec.Header().Get("Location"))
+	}
+	if rec := serve(handler, http.MethodPost, "/wallet/add/ok"); rec.Code != http.StatusGone {
+		t.Errorf("second POST status = %d, want %d", rec.Code, http.StatusGone)
+	}
+	if rec := serve(handler, http.MethodPut, "/wallet/add/ok"); rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("PUT status = %d, want %d", rec.Code, http.StatusMethodNotAllowed)
+	}
+}
+
+func TestRedirectHandlerWithoutConfirmStepRejectsPost(t *testing.T) {
+	store := wallet.NewMemoryCardStore()
+	store.Put("ok", storedCard("card-1"))
+	handler := newRedirectClient(t).NewRedirectHandler(store)
+
+	rec := serve(handler, http.MethodPost, "/wallet/add/ok")
+	if rec.Code != http.StatusMethodNotAllowed || rec.Header().Get("Allow") != http.MethodGet {
+		t.Errorf("POST status = %d, Allow = %q, want 405 allowing GET", rec.Code, rec.Header().Get("Allow"))
+	}
+}
+
+func TestRedirectHandlerRateLimit(t *testing.T) {
+	store := wallet.NewMemoryCardStore()
+	store.Put("ok", storedCard("card-1"))
+	handler := newRedirectClient(t).NewRedirectHandler(store, wallet.WithRateLimit(2, time.Minute))
+
+	for i := 0; i < 2; i++ {
+		if rec := serve(handler, http.MethodGet, "/wallet/add/ok"); rec.Code != http.StatusFound {
+			t.Fatalf("request %d status = %d, want %d", i, rec.Code, http.StatusFound)
+		}
+	}
+	rec := serve(handler, http.MethodGet, "/wallet/add/ok")
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("third request status = %d, want %d", rec.Code, http.StatusTooManyRequests)
+	}
+	if got := rec.Header().Get("Retry-After"); got != "30" {
+		t.Errorf("Retry-After = %q, want 30", got)
+	}
+}
+
+func TestMemoryCardStore(t *testing.T) {
+	ctx := context.Background()
+	store := wallet.NewMemoryCardStore()
+	store.Put("t", storedCard("card-1"))
+
+	if _, err := store.ConsumeCard(ctx, "t"); err != nil {
+		t.Fatalf("ConsumeCard() error = %v", err)
+	}
+	if _, err := store.ConsumeCard(ctx, "t"); !errors.Is(err, wallet.ErrTokenUsed) {
+		t.Errorf("second ConsumeCard() error = %v, want ErrTokenUsed", err)
+	}
+	store.Put("t", storedCard("card-1"))
+	if _, err := store.ConsumeCard(ctx, "t"); err != nil {
+		t.Errorf("ConsumeCard() after Put error = %v", err)
+	}
+	store.Delete("t")
+	if _, err := store.LookupCard(ctx, "t"); !errors.Is(err, wallet.ErrTokenNotFound) {
+		t.Errorf("LookupCard() after Delete error = %v, want ErrTokenNotFound", err)
+	}
+}
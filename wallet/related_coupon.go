@@ -0,0 +1,104 @@
+package wallet
+
+import (
+	"fmt"
+	"time"
+)
+
+// MaxRelatedCoupons is the number of related coupons a card can carry
+const MaxRelatedCoupons = 3
+
+// RelatedCoupon is a coupon attached to a card, e.g. a concession voucher on a ticket
+// It is sent as the relCoupon1-3 attributes
+type RelatedCoupon struct {
+	Title             string       // Coupon title
+	Subtitle          string       // Coupon subtitle
+	ProviderName      string       // Coupon provider name
+	ImageURL          string       // Coupon image URL (imageFileSrc)
+	NoticeDescription string       // Notice text; HTML like noticeDesc
+	NotificationTime  time.Time    // When the user is reminded of the coupon, sent with its UTC offset; zero for none
+	Barcode           *BarcodeSpec // Optional barcode, its value sent as relCouponN.value
+}
+
+// AddRelatedCoupon attaches a related coupon; at most MaxRelatedCoupons can be added,
+// further coupons are dropped and reported by BuildStrict
+func (b *EventTicketBuilder) AddRelatedCoupon(coupon RelatedCoupon) *EventTicketBuilder {
+	if len(b.relatedCoupons) >= MaxRelatedCoupons {
+		b.errs = append(b.errs, fmt.Errorf("at most %d related coupons are allowed, dropped %q", MaxRelatedCoupons, coupon.Title))
+		return b
+	}
+	if coupon.Barcode != nil {
+		if err := coupon.Barcode.Validate(); err != nil {
+			b.errs = append(b.errs, fmt.Errorf("invalid barcode of related coupon %q: %v", coupon.Title, err))
+		}
+	}
+	b.relatedCoupons = append(b.relatedCoupons, coupon)
+	return b
+}
+
+// applyRelatedCoupons sets the relCouponN attributes from coupons
+func applyRelatedCoupons(attributes *TicketAttributes, coupons []RelatedCoupon) {
+	for i, coupon := range coupons {
+		var f relatedCouponFields
+		switch i {
+		case 0:
+			f = relatedCouponFields{
+				&attributes.RelCoupon1Title, &attributes.RelCoupon1Subtitle, &attributes.RelCoupon1ProviderName,
+				&attributes.RelCoupon1ImageFileSrc, &attributes.RelCoupon1NoticeDescription,
+				&attributes.RelCoupon1NotificationTime, &attributes.RelCoupon1NotificationTimeUTCOffset,
+				&attributes.RelCoupon1Value, &attributes.RelCoupon1SerialType, &attributes.RelCoupon1PTFormat,
+				&attributes.RelCoupon1PTSubFormat, &attributes.RelCoupon1ErrorCorrectionLevel,
+			}
+		case 1:
+			f = relatedCouponFields{
+				&attributes.RelCoupon2Title, &attributes.RelCoupon2Subtitle, &attributes.RelCoupon2ProviderName,
+				&attributes.RelCoupon2ImageFileSrc, &attributes.RelCoupon2NoticeDescription,
+				&attributes.RelCoupon2NotificationTime, &attributes.RelCoupon2NotificationTimeUTCOffset,
+				&attributes.RelCoupon2Value, &attributes.RelCoupon2SerialType, &attributes.RelCoupon2PTFormat,
+				&attributes.RelCoupon2PTSubFormat, &attributes.RelCoupon2ErrorCorrectionLevel,
+			}
+		case 2:
+			f = relatedCouponFields{
+				&attributes.RelCoupon3Title, &attributes.RelCoupon3Subtitle, &attributes.RelCoupon3ProviderName,
+				&attributes.RelCoupon3ImageFileSrc, &attributes.RelCoupon3NoticeDescription,
+				&attributes.RelCoupon3NotificationTime, &attributes.RelCoupon3NotificationTimeUTCOffset,
+				&attributes.RelCoupon3Value, &attributes.RelCoupon3SerialType, &attributes.RelCoupon3PTFormat,
+				&attributes.RelCoupon3PTSubFormat, &attributes.RelCoupon3ErrorCorrectionLevel,
+			}
+		default:
+			return
+		}
+		f.set(coupon)
+	}
+}
+
+// relatedCouponFields points at the TicketAttributes fields of one related coupon
+type relatedCouponFields struct {
+	title, subtitle, providerName, imageFileSrc, noticeDescription *string
+	notificationTime                                               *int64
+	notificationTimeUTCOffset                                      *string
+	value, serialType, ptFormat, ptSubFormat, errorCorrectionLevel *string
+}
+
+// set copies a coupon into the fields
+func (f relatedCouponFields) set(coupon RelatedCoupon) {
+	*f.title = coupon.Title
+	*f.subtitle = coupon.Subtitle
+	*f.providerName = coupon.ProviderName
+	*f.imageFileSrc = coupon.ImageURL
+	*f.noticeDescription = coupon.NoticeDescription
+	*f.notificationTime, *f.notificationTimeUTCOffset = 0, ""
+	if !coupon.NotificationTime.IsZero() {
+		*f.notificationTime = coupon.NotificationTime.UnixMilli()
+		*f.notificationTimeUTCOffset = FormatUTCOffset(coupon.NotificationTime)
+	}
+
+	*f.value, *f.serialType, *f.ptFormat, *f.ptSubFormat, *f.errorCorrectionLevel = "", "", "", "", ""
+	if coupon.Barcode != nil {
+		*f.value = coupon.Barcode.Value
+		*f.serialType = coupon.Barcode.SerialType()
+		*f.ptFormat = coupon.Barcode.PTFormat()
+		*f.ptSubFormat = coupon.Barcode.PTSubFormat()
+		*f.errorCorrectionLevel = string(coupon.Barcode.ErrorCorrectionLevel)
+	}
+}
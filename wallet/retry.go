@@ -0,0 +1,137 @@
+package wallet
+
+import (
+	"errors"
+	"math"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy controls how failed Samsung server API calls are retried
+//
+// Only failures that are safe to repeat are retried: responses that tell us the
+// request was not processed (429, 503) and connection failures that happened
+// before anything was sent. Other 5xx responses, timeouts and dropped connections
+// are retried only for idempotent calls such as update and cancel notifications.
+type RetryPolicy struct {
+	MaxAttempts    int           // Total attempts including the first one (1 disables retries)
+	InitialBackoff time.Duration // Delay before the first retry
+	MaxBackoff     time.Duration // Upper bound for a delay; a longer Retry-After ends the retries
+	Multiplier     float64       // Growth factor applied to the delay after each attempt
+	Jitter         float64       // Fraction (0-1) of each delay that is randomized
+
+	// OnAttempt is called after every failed attempt, before sleeping for a retry
+	OnAttempt func(RetryAttempt)
+}
+
+// RetryAttempt describes a failed attempt reported to RetryPolicy.OnAttempt
+type RetryAttempt struct {
+	Attempt    int           // 1-based attempt number
+	Method     string        // HTTP method
+	URL        string        // Request URL
+	StatusCode int           // HTTP status code, 0 when the request failed in transport
+	Err        error         // Error that will be returned if no retry follows
+	WillRetry  bool          // Whether another attempt will be made
+	Delay      time.Duration // Delay before the next attempt
+}
+
+// defaultMaxBackoff bounds delays when the policy sets no MaxBackoff
+const defaultMaxBackoff = 10 * time.Second
+
+// DefaultRetryPolicy returns the retry policy used by new clients
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: 500 * time.Millisecond,
+		MaxBackoff:     defaultMaxBackoff,
+		Multiplier:     2,
+		Jitter:         0.2,
+	}
+}
+
+// maxAttempts returns the number of attempts allowed by the policy
+func (p *RetryPolicy) maxAttempts() int {
+	if p == nil || p.MaxAttempts < 1 {
+		return 1
+	}
+	return p.MaxAttempts
+}
+
+// backoff returns the delay before the retry that follows the given attempt
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = 2
+	}
+
+	delay := float64(p.InitialBackoff) * math.Pow(multiplier, float64(attempt-1))
+	if p.MaxBackoff > 0 && delay > float64(p.MaxBackoff) {
+		delay = float64(p.MaxBackoff)
+	}
+
+	if jitter := math.Min(math.Max(p.Jitter, 0), 1); jitter > 0 {
+		// Spread the delay over [delay*(1-jitter), delay*(1+jitter)]
+		delay += delay * jitter * (2*rand.Float64() - 1)
+	}
+
+	return time.Duration(delay)
+}
+
+// maxDelay returns the longest delay the policy waits before a retry
+func (p *RetryPolicy) maxDelay() time.Duration {
+	if p.MaxBackoff > 0 {
+		return p.MaxBackoff
+	}
+	return defaultMaxBackoff
+}
+
+// isRetryableStatus reports whether an HTTP status may be retried
+func isRetryableStatus(statusCode int, idempotent bool) bool {
+	switch statusCode {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		// Samsung rejected the request without processing it
+		return true
+	case http.StatusRequestTimeout, http.StatusInternalServerError, http.StatusBadGateway, http.StatusGatewayTimeout:
+		return idempotent
+	default:
+		return false
+	}
+}
+
+// isRetryableTransportError reports whether a transport error may be retried
+func isRetryableTransportError(err error, idempotent bool) bool {
+	if idempotent {
+		return true
+	}
+
+	// A failed dial means nothing reached the server
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// parseRetryAfter parses a Retry-After header given in seconds or as an HTTP date
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		delay := date.Sub(now)
+		if delay < 0 {
+			delay = 0
+		}
+		return delay, true
+	}
+
+	return 0, false
+}
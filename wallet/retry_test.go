@@ -0,0 +1,87 @@
+package wallet
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestIsRetryableStatus(t *testing.T) {
+	tests := []struct {
+		status     int
+		idempotent bool
+		want       bool
+	}{
+		{http.StatusTooManyRequests, false, true},
+		{http.StatusServiceUnavailable, false, true},
+		{http.StatusInternalServerError, true, true},
+		{http.StatusInternalServerError, false, false},
+		{http.StatusBadGateway, false, false},
+		{http.StatusGatewayTimeout, true, true},
+		{http.StatusRequestTimeout, false, false},
+		{http.StatusBadRequest, true, false},
+		{http.StatusUnauthorized, true, false},
+		{http.StatusNotFound, true, false},
+	}
+	for _, tt := range tests {
+		if got := isRetryableStatus(tt.status, tt.idempotent); got != tt.want {
+			t.Errorf("isRetryableStatus(%d, %v) = %v, want %v", tt.status, tt.idempotent, got, tt.want)
+		}
+	}
+}
+
+func TestIsRetryableTransportError(t *testing.T) {
+	dial := &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+	read := &net.OpError{Op: "read", Net: "tcp", Err: errors.New("connection reset by peer")}
+
+	tests := map[string]struct {
+		err        error
+		idempotent bool
+		want       bool
+	}{
+		"dial":             {dial, false, true},
+		"wrapped dial":     {&wrappedError{dial}, false, true},
+		"read":             {read, false, false},
+		"read idempotent":  {read, true, true},
+		"other":            {errors.New("EOF"), false, false},
+		"other idempotent": {errors.New("EOF"), true, true},
+	}
+	for name, tt := range tests {
+		if got := isRetryableTransportError(tt.err, tt.idempotent); got != tt.want {
+			t.Errorf("%s: isRetryableTransportError() = %v, want %v", name, got, tt.want)
+		}
+	}
+}
+
+// wrappedError wraps an error the way net/http wraps transport errors
+type wrappedError struct{ err error }
+
+func (e *wrappedError) Error() string { return "Post: " + e.err.Error() }
+func (e *wrappedError) Unwrap() error { return e.err }
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := map[string]struct {
+		value  string
+		want   time.Duration
+		wantOK bool
+	}{
+		"empty":      {"", 0, false},
+		"seconds":    {"120", 2 * time.Minute, true},
+		"zero":       {"0", 0, true},
+		"negative":   {"-5", 0, false},
+		"http date":  {now.Add(90 * time.Second).Format(http.TimeFormat), 90 * time.Second, true},
+		"past date":  {now.Add(-time.Hour).Format(http.TimeFormat), 0, true},
+		"fractional": {"1.5", 0, false},
+		"garbage":    {"soon", 0, false},
+		"rfc3339":    {"2024-01-01T12:01:00Z", 0, false},
+	}
+	for name, tt := range tests {
+		got, ok := parseRetryAfter(tt.value, now)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("%s: parseRetryAfter(%q) = (%v, %v), want (%v, %v)", name, tt.value, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
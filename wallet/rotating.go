@@ -0,0 +1,154 @@
+package wallet
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// defaultRotationInterval is how long each rotating barcode value is valid
+	defaultRotationInterval = 30 * time.Second
+
+	// defaultRotatingDigits is the number of digits of a rotating code
+	defaultRotatingDigits = 8
+
+	// Range of code lengths; the 31-bit HOTP value only has 9 uniformly distributed digits
+	minRotatingDigits = 6
+	maxRotatingDigits = 9
+
+	// rotatingSecretSize is the size of generated secrets, as recommended by RFC 4226
+	rotatingSecretSize = 20
+)
+
+// rotatingSecretEncoding encodes secrets in provision.data like authenticator apps do
+var rotatingSecretEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// RotatingBarcode derives time-windowed barcode values from a per-ticket secret,
+// following TOTP (RFC 6238) so screenshots of a ticket stop working after one interval
+// The device computes the same values from provision.data, so the secret travels in
+// the card data; keep it unique per ticket and never reuse it for anything else
+type RotatingBarcode struct {
+	Secret   []byte        // Per-ticket secret
+	Interval time.Duration // Rotation interval, whole seconds (default 30s)
+	Digits   int           // Code length, 6-9 digits (default 8)
+	Prefix   string        // Static prefix of every value, e.g. the refId and a separator, so scanners can find the secret
+}
+
+// GenerateRotatingSecret returns a new random secret for a RotatingBarcode
+func GenerateRotatingSecret() ([]byte, error) {
+	secret := make([]byte, rotatingSecretSize)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("failed to generate secret: %v", err)
+	}
+	return secret, nil
+}
+
+// ParseRotatingSecret decodes a secret from its provision.data form
+func ParseRotatingSecret(s string) ([]byte, error) {
+	secret, err := rotatingSecretEncoding.DecodeString(strings.ToUpper(strings.TrimRight(s, "=")))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode secret: %v", err)
+	}
+	return secret, nil
+}
+
+// interval returns the rotation interval, defaulting to 30 seconds
+func (r *RotatingBarcode) interval() time.Duration {
+	if r.Interval < time.Second {
+		return defaultRotationInterval
+	}
+	return r.Interval.Truncate(time.Second)
+}
+
+// digits returns the code length, defaulting to 8
+func (r *RotatingBarcode) digits() int {
+	if r.Digits < minRotatingDigits || r.Digits > maxRotatingDigits {
+		return defaultRotatingDigits
+	}
+	return r.Digits
+}
+
+// Step returns the time step at t
+func (r *RotatingBarcode) Step(t time.Time) int64 {
+	return t.Unix() / int64(r.interval()/time.Second)
+}
+
+// Value returns the barcode value valid at t
+func (r *RotatingBarcode) Value(t time.Time) string {
+	return r.Prefix + r.code(r.Step(t))
+}
+
+// code computes the HOTP code (RFC 4226) of a time step
+func (r *RotatingBarcode) code(step int64) string {
+	var counter [8]byte
+	binary.BigEndian.PutUint64(counter[:], uint64(step))
+
+	mac := hmac.New(sha1.New, r.Secret)
+	mac.Write(counter[:])
+	sum := mac.Sum(nil)
+
+	// Dynamic truncation
+	offset := sum[len(sum)-1] & 0x0F
+	value := int64(binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7FFFFFFF)
+
+	digits := r.digits()
+	modulus := int64(1)
+	for i := 0; i < digits; i++ {
+		modulus *= 10
+	}
+	return fmt.Sprintf("%0*d", digits, value%modulus)
+}
+
+// Verify reports whether value is valid at t, accepting values from up to window
+// intervals before or after t to allow for clock drift, and returns the matched step
+// A negative window is treated as 0
+// Scanners should remember the last accepted step per ticket to reject replays
+func (r *RotatingBarcode) Verify(value string, t time.Time, window int) (int64, bool) {
+	code, ok := strings.CutPrefix(value, r.Prefix)
+	if !ok || len(code) != r.digits() {
+		return 0, false
+	}
+	window = max(window, 0)
+
+	current := r.Step(t)
+	for delta := 0; delta <= window; delta++ {
+		for _, step := range []int64{current - int64(delta), current + int64(delta)} {
+			if subtle.ConstantTimeCompare([]byte(r.code(step)), []byte(code)) == 1 {
+				return step, true
+			}
+			if delta == 0 {
+				break
+			}
+		}
+	}
+	return 0, false
+}
+
+// validate checks the secret and code length
+func (r *RotatingBarcode) validate() error {
+	if len(r.Secret) == 0 {
+		return fmt.Errorf("rotating barcode secret is required")
+	}
+	if r.Digits != 0 && (r.Digits < minRotatingDigits || r.Digits > maxRotatingDigits) {
+		return fmt.Errorf("rotating barcode digits must be %d-%d, got %d", minRotatingDigits, maxRotatingDigits, r.Digits)
+	}
+	return nil
+}
+
+// Attributes returns the barcode.interval, provision.data and provision.interval attributes
+func (r *RotatingBarcode) Attributes() map[string]string {
+	interval := strconv.FormatInt(int64(r.interval()/time.Second), 10)
+	return map[string]string{
+		"barcode.interval":   interval,
+		"provision.data":     rotatingSecretEncoding.EncodeToString(r.Secret),
+		"provision.interval": interval,
+	}
+}
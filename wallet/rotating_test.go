@@ -0,0 +1,123 @@
+package wallet
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfcSecret is the SHA-1 test secret of RFC 4226 and RFC 6238
+var rfcSecret = []byte("12345678901234567890")
+
+func TestRotatingBarcodeRFC6238(t *testing.T) {
+	r := &RotatingBarcode{Secret: rfcSecret, Digits: 8}
+
+	// RFC 6238 Appendix B, SHA-1
+	tests := map[int64]string{
+		59:          "94287082",
+		1111111109:  "07081804",
+		1111111111:  "14050471",
+		1234567890:  "89005924",
+		2000000000:  "69279037",
+		20000000000: "65353130",
+	}
+	for unix, want := range tests {
+		if got := r.Value(time.Unix(unix, 0)); got != want {
+			t.Errorf("Value(%d) = %s, want %s", unix, got, want)
+		}
+	}
+}
+
+func TestRotatingBarcodeRFC4226(t *testing.T) {
+	r := &RotatingBarcode{Secret: rfcSecret, Digits: 6}
+
+	// RFC 4226 Appendix D
+	want := []string{"755224", "287082", "359152", "969429", "338314", "254676", "287922", "162583", "399871", "520489"}
+	for step, code := range want {
+		if got := r.code(int64(step)); got != code {
+			t.Errorf("code(%d) = %s, want %s", step, got, code)
+		}
+	}
+}
+
+func TestRotatingBarcodeDigits(t *testing.T) {
+	tests := map[int]int{0: 8, 5: 8, 6: 6, 9: 9, 10: 8}
+	for digits, want := range tests {
+		r := &RotatingBarcode{Secret: rfcSecret, Digits: digits}
+		if got := len(r.Value(time.Unix(59, 0))); got != want {
+			t.Errorf("Digits %d: len(Value()) = %d, want %d", digits, got, want)
+		}
+	}
+}
+
+func TestRotatingBarcodeVerify(t *testing.T) {
+	r := &RotatingBarcode{Secret: rfcSecret, Interval: time.Minute, Prefix: "T1:"}
+	now := time.Unix(1700000000, 0)
+	value := r.Value(now)
+	if !strings.HasPrefix(value, "T1:") {
+		t.Fatalf("Value() = %q, want prefix T1:", value)
+	}
+
+	if step, ok := r.Verify(value, now, 0); !ok || step != r.Step(now) {
+		t.Errorf("Verify() = %d, %v, want %d, true", step, ok, r.Step(now))
+	}
+	if _, ok := r.Verify(value, now.Add(time.Minute), 0); ok {
+		t.Error("Verify() accepted a value from the previous interval with window 0")
+	}
+	if _, ok := r.Verify(value, now.Add(time.Minute), 1); !ok {
+		t.Error("Verify() rejected a value from the previous interval with window 1")
+	}
+	if _, ok := r.Verify(value, now.Add(-time.Minute), 1); !ok {
+		t.Error("Verify() rejected a value from the next interval with window 1")
+	}
+	if _, ok := r.Verify(value, now.Add(2*time.Minute), 1); ok {
+		t.Error("Verify() accepted a value two intervals old with window 1")
+	}
+	if _, ok := r.Verify(value, now.Add(time.Minute), -5); ok {
+		t.Error("Verify() with a negative window accepted a value from the previous interval")
+	}
+	if _, ok := r.Verify(value, now, -5); !ok {
+		t.Error("Verify() with a negative window rejected the current value")
+	}
+	if _, ok := r.Verify(strings.TrimPrefix(value, "T1:"), now, 0); ok {
+		t.Error("Verify() accepted a value without prefix")
+	}
+}
+
+func TestRotatingSecretRoundTrip(t *testing.T) {
+	secret, err := GenerateRotatingSecret()
+	if err != nil {
+		t.Fatalf("GenerateRotatingSecret() error = %v", err)
+	}
+	r := &RotatingBarcode{Secret: secret, Interval: 45 * time.Second}
+	attributes := r.Attributes()
+	if attributes["barcode.interval"] != "45" || attributes["provision.interval"] != "45" {
+		t.Errorf("Attributes() = %v, want intervals of 45", attributes)
+	}
+
+	parsed, err := ParseRotatingSecret(strings.ToLower(attributes["provision.data"]))
+	if err != nil || string(parsed) != string(secret) {
+		t.Errorf("ParseRotatingSecret() = %x, %v, want %x", parsed, err, secret)
+	}
+}
+
+func TestSetRotatingBarcodeErrors(t *testing.T) {
+	tests := map[string]*RotatingBarcode{
+		"nil":       nil,
+		"no secret": {},
+		"digits":    {Secret: rfcSecret, Digits: 10},
+	}
+	for name, r := range tests {
+		if _, err := newTestTicket().SetRotatingBarcode(r).BuildStrict(); err == nil {
+			t.Errorf("%s: BuildStrict() succeeded", name)
+		}
+	}
+
+	card, err := newTestTicket().SetRotatingBarcode(&RotatingBarcode{Secret: rfcSecret}).BuildStrict()
+	if err != nil {
+		t.Fatalf("BuildStrict() error = %v", err)
+	}
+	if got := card.Card.Data[0].Attributes["provision.interval"]; got != "30" {
+		t.Errorf("provision.interval = %v, want 30", got)
+	}
+}
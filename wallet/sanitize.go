@@ -0,0 +1,166 @@
+package wallet
+
+import (
+	"html"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// MaxNoticeLength is the maximum length of noticeDesc and relCouponN.noticeDescription
+const MaxNoticeLength = 1024
+
+// noticeTags are the HTML tags Samsung Wallet renders in notices
+// Attributes are never kept, so no tag can carry scripts, styles or links
+var noticeTags = map[string]bool{
+	"b":      true,
+	"strong": true,
+	"i":      true,
+	"em":     true,
+	"u":      true,
+	"br":     true,
+	"p":      true,
+	"ul":     true,
+	"ol":     true,
+	"li":     true,
+}
+
+// voidTags are allowed tags without content or closing tag
+var voidTags = map[string]bool{
+	"br": true,
+}
+
+var (
+	// tagPattern matches a start, end or self-closing tag at the start of the input
+	tagPattern = regexp.MustCompile(`^<(/?)([a-zA-Z][a-zA-Z0-9]*)\b[^<>]*?(/?)>`)
+
+	// entityPattern matches a character reference at the start of the input
+	entityPattern = regexp.MustCompile(`^&(#[0-9]{1,7}|#[xX][0-9a-fA-F]{1,6}|[a-zA-Z][a-zA-Z0-9]{1,31});`)
+)
+
+// SanitizeNotice makes text safe for a notice field: it keeps the tags Samsung Wallet
+// renders (without attributes), escapes everything else and truncates the result to
+// MaxNoticeLength characters, closing any open tags
+func SanitizeNotice(input string) string {
+	result, _ := SanitizeNoticeLimit(input, MaxNoticeLength)
+	return result
+}
+
+// SanitizeNoticeLimit sanitizes like SanitizeNotice with a custom length limit and
+// reports whether the text had to be truncated
+func SanitizeNoticeLimit(input string, limit int) (string, bool) {
+	s := &noticeSanitizer{limit: limit}
+
+	for rest := input; rest != ""; {
+		switch {
+		case rest[0] == '<':
+			if m := tagPattern.FindStringSubmatch(rest); m != nil {
+				s.tag(m[1] == "/", strings.ToLower(m[2]), m[3] == "/", m[0])
+				rest = rest[len(m[0]):]
+				continue
+			}
+			s.text("&lt;")
+			rest = rest[1:]
+		case rest[0] == '&':
+			// Keep character references as written instead of double escaping them
+			if m := entityPattern.FindString(rest); m != "" {
+				s.text(m)
+				rest = rest[len(m):]
+				continue
+			}
+			s.text("&amp;")
+			rest = rest[1:]
+		default:
+			r, size := utf8.DecodeRuneInString(rest)
+			s.text(html.EscapeString(string(r)))
+			rest = rest[size:]
+		}
+		if s.truncated {
+			break
+		}
+	}
+
+	return s.close(), s.truncated
+}
+
+// noticeSanitizer builds sanitized notice HTML within a length budget
+type noticeSanitizer struct {
+	out       strings.Builder
+	length    int      // Characters written to out
+	open      []string // Open tags, innermost last
+	limit     int
+	truncated bool
+}
+
+// closingLength returns the length of the end tags needed to close all open tags
+func (s *noticeSanitizer) closingLength() int {
+	n := 0
+	for _, name := range s.open {
+		n += len(name) + 3
+	}
+	return n
+}
+
+// write appends a token if it fits in the budget together with the closing tags
+func (s *noticeSanitizer) write(token string, extraClosing int) bool {
+	n := utf8.RuneCountInString(token)
+	if s.length+n+s.closingLength()+extraClosing > s.limit {
+		s.truncated = true
+		return false
+	}
+	s.out.WriteString(token)
+	s.length += n
+	return true
+}
+
+// text appends escaped text
+func (s *noticeSanitizer) text(escaped string) {
+	if !s.truncated {
+		s.write(escaped, 0)
+	}
+}
+
+// tag appends an allowed tag without attributes, or escapes a disallowed one
+func (s *noticeSanitizer) tag(end bool, name string, selfClosing bool, raw string) {
+	if s.truncated {
+		return
+	}
+	if !noticeTags[name] {
+		s.write(html.EscapeString(raw), 0)
+		return
+	}
+
+	switch {
+	case voidTags[name]:
+		s.write("<"+name+">", 0)
+	case end:
+		// Drop end tags that don't match an open tag; close tags left open inside
+		for i := len(s.open) - 1; i >= 0; i-- {
+			if s.open[i] != name {
+				continue
+			}
+			for len(s.open) > i {
+				last := s.open[len(s.open)-1]
+				s.open = s.open[:len(s.open)-1]
+				s.out.WriteString("</" + last + ">")
+				s.length += len(last) + 3
+			}
+			return
+		}
+	case selfClosing:
+		// <b/> has no content
+	default:
+		if s.write("<"+name+">", len(name)+3) {
+			s.open = append(s.open, name)
+		}
+	}
+}
+
+// close closes all open tags and returns the result
+func (s *noticeSanitizer) close() string {
+	for i := len(s.open) - 1; i >= 0; i-- {
+		s.out.WriteString("</" + s.open[i] + ">")
+	}
+	s.open = nil
+	return s.out.String()
+}
@@ -0,0 +1,77 @@
+package wallet
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestSanitizeNotice(t *testing.T) {
+	tests := map[string]struct {
+		input string
+		want  string
+	}{
+		"allowed tags":     {`<b>Gate</b> opens<br/>at <EM>6pm</EM>`, `<b>Gate</b> opens<br>at <em>6pm</em>`},
+		"attributes":       {`<p onclick="x" style="y">Hi</p>`, `<p>Hi</p>`},
+		"script":           {`<script>alert(1)</script>`, `&lt;script&gt;alert(1)&lt;/script&gt;`},
+		"link":             {`<a href="https://x">x</a>`, `&lt;a href=&#34;https://x&#34;&gt;x&lt;/a&gt;`},
+		"unclosed":         {`<ul><li>One`, `<ul><li>One</li></ul>`},
+		"stray end":        {`One</b>`, `One`},
+		"misnested":        {`<b><i>x</b>y</i>`, `<b><i>x</i></b>y`},
+		"entities kept":    {`Tom &amp; Jerry &#8482; &copy;`, `Tom &amp; Jerry &#8482; &copy;`},
+		"bare ampersand":   {`R&D < 5`, `R&amp;D &lt; 5`},
+		"already escaped":  {`&lt;script&gt;`, `&lt;script&gt;`},
+		"self closing tag": {`<b/>x`, `x`},
+	}
+	for name, tt := range tests {
+		if got := SanitizeNotice(tt.input); got != tt.want {
+			t.Errorf("%s: SanitizeNotice(%q) = %q, want %q", name, tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestSanitizeNoticeLimit(t *testing.T) {
+	got, truncated := SanitizeNoticeLimit("<b>"+strings.Repeat("가", 20)+"</b>", 15)
+	if !truncated || got != "<b>"+strings.Repeat("가", 8)+"</b>" {
+		t.Errorf("SanitizeNoticeLimit() = %q, %v", got, truncated)
+	}
+	if n := utf8.RuneCountInString(got); n > 15 {
+		t.Errorf("SanitizeNoticeLimit() length = %d, want at most 15", n)
+	}
+
+	if _, truncated := SanitizeNoticeLimit("short", 15); truncated {
+		t.Error("SanitizeNoticeLimit() truncated short text")
+	}
+}
+
+func TestNoticeSanitizationCoversLocalizations(t *testing.T) {
+	localized := map[string]interface{}{
+		"noticeDesc":                   `<script>x</script>`,
+		"relCoupon1.noticeDescription": `<i onclick="x">Free</i>`,
+		"title":                        `<b>콘서트</b>`,
+	}
+	card := newTestTicket().
+		SetNoticeDescription(`<b onclick="x">Notice</b>`).
+		AddRelatedCoupon(RelatedCoupon{Title: "Drink", NoticeDescription: "<u>Free</u>"}).
+		AddLocalization("ko", localized).
+		SetNoticeSanitization(true).
+		Build()
+
+	data := card.Card.Data[0]
+	if got := data.Attributes["noticeDesc"]; got != "<b>Notice</b>" {
+		t.Errorf("noticeDesc = %q", got)
+	}
+	attributes := data.Localization[0].Attributes
+	if got := attributes["noticeDesc"]; got != "&lt;script&gt;x&lt;/script&gt;" {
+		t.Errorf("localized noticeDesc = %q", got)
+	}
+	if got := attributes["relCoupon1.noticeDescription"]; got != "<i>Free</i>" {
+		t.Errorf("localized relCoupon1.noticeDescription = %q", got)
+	}
+	if got := attributes["title"]; got != "<b>콘서트</b>" {
+		t.Errorf("localized title = %q, want it left alone", got)
+	}
+	if got := localized["noticeDesc"]; got != "<script>x</script>" {
+		t.Errorf("caller's localization was modified: %q", got)
+	}
+}
@@ -0,0 +1,162 @@
+// Package testkeys generates matching partner and Samsung test credentials at runtime
+package testkeys
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/go-jose/go-jose/v3"
+
+	"github.com/abyssparanoia/samsung-wallet-go/wallet"
+)
+
+const (
+	// Default credentials used in generated configurations
+	DefaultPartnerID     = "test-partner"
+	DefaultCertificateID = "TEST"
+
+	// PlaceholderAPIBaseURL is the server API base URL of generated configurations
+	// The .invalid domain never resolves, so stray calls fail instead of reaching Samsung
+	PlaceholderAPIBaseURL = "https://samsung-wallet.invalid"
+
+	// keyBits is the RSA key size of generated keys
+	keyBits = 2048
+
+	// certificateLifetime is how long generated certificates stay valid
+	certificateLifetime = 24 * time.Hour
+)
+
+// Fixture holds a matching set of partner and "Samsung" keys and certificates
+//
+// The partner key signs CDATA and authorization tokens, the Samsung key decrypts
+// CDATA and signs calls to partner servers, exactly like the real key pairs.
+type Fixture struct {
+	PartnerID     string // Partner ID used by Config
+	CertificateID string // Certificate ID used by Config
+
+	PartnerKey     *rsa.PrivateKey
+	PartnerKeyPEM  string // PKCS#8 PEM
+	PartnerCertPEM string // Self-signed certificate for PartnerKey
+
+	SamsungKey     *rsa.PrivateKey
+	SamsungKeyPEM  string // PKCS#8 PEM
+	SamsungCertPEM string // Self-signed certificate for SamsungKey
+}
+
+// Generate creates a new fixture with fresh RSA keys and self-signed certificates
+func Generate() (*Fixture, error) {
+	partnerKey, partnerKeyPEM, partnerCertPEM, err := generateKeyPair("Samsung Wallet Test Partner")
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate partner key pair: %v", err)
+	}
+
+	samsungKey, samsungKeyPEM, samsungCertPEM, err := generateKeyPair("Samsung Wallet Test")
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate samsung key pair: %v", err)
+	}
+
+	return &Fixture{
+		PartnerID:      DefaultPartnerID,
+		CertificateID:  DefaultCertificateID,
+		PartnerKey:     partnerKey,
+		PartnerKeyPEM:  partnerKeyPEM,
+		PartnerCertPEM: partnerCertPEM,
+		SamsungKey:     samsungKey,
+		SamsungKeyPEM:  samsungKeyPEM,
+		SamsungCertPEM: samsungCertPEM,
+	}, nil
+}
+
+// MustGenerate is like Generate but panics on failure
+func MustGenerate() *Fixture {
+	fixture, err := Generate()
+	if err != nil {
+		panic(err)
+	}
+	return fixture
+}
+
+// Config returns a ready-to-use client configuration for the fixture's keys
+// Server API calls go to PlaceholderAPIBaseURL until APIBaseURL is set, e.g. to a
+// wallettest server, so test keys are never sent to production
+func (f *Fixture) Config() *wallet.Config {
+	return &wallet.Config{
+		APIBaseURL:        PlaceholderAPIBaseURL,
+		PartnerID:         f.PartnerID,
+		PartnerPrivateKey: f.PartnerKeyPEM,
+		SamsungPublicKey:  f.SamsungCertPEM,
+		CertificateID:     f.CertificateID,
+	}
+}
+
+// Client creates a client from the fixture's configuration
+func (f *Fixture) Client() (*wallet.Client, error) {
+	return wallet.NewClient(f.Config())
+}
+
+// DecryptCDATA verifies the partner signature of CDATA and decrypts its card data
+// with the Samsung key, returning the JSON the client encrypted
+func (f *Fixture) DecryptCDATA(cdata string) ([]byte, error) {
+	jws, err := jose.ParseSigned(cdata)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse CDATA: %v", err)
+	}
+
+	jwePayload, err := jws.Verify(&f.PartnerKey.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify CDATA signature: %v", err)
+	}
+
+	jwe, err := jose.ParseEncrypted(string(jwePayload))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse CDATA payload: %v", err)
+	}
+
+	plaintext, err := jwe.Decrypt(f.SamsungKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt CDATA: %v", err)
+	}
+	return plaintext, nil
+}
+
+// generateKeyPair creates an RSA key with a self-signed certificate, both PEM encoded
+func generateKeyPair(commonName string) (*rsa.PrivateKey, string, string, error) {
+	key, err := rsa.GenerateKey(rand.Reader, keyBits)
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 62))
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     now.Add(certificateLifetime),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+	}
+
+	certDER, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	keyPEM := string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}))
+	certPEM := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDER}))
+	return key, keyPEM, certPEM, nil
+}
@@ -0,0 +1,185 @@
+package wallet
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Theme is a named set of card colors, e.g. a brand's palette
+type Theme struct {
+	Name       string
+	Background Color     // bgColor
+	Font       FontColor // fontColor; empty derives light or dark from Background
+	Blink      *Color    // blinkColor; nil leaves it unset
+}
+
+// FontColor returns the theme's font color, deriving it from the background if unset
+func (t Theme) FontColor() (FontColor, error) {
+	if t.Font == "" {
+		return FontColorFor(t.Background), nil
+	}
+	c, err := t.Font.Color()
+	if err != nil {
+		return "", err
+	}
+	if t.Font == FontColorLight || t.Font == FontColorDark {
+		return t.Font, nil
+	}
+	return FontColorOf(c), nil
+}
+
+// Styling returns the bgColor, fontColor and blinkColor attribute values
+func (t Theme) Styling() (bgColor, fontColor, blinkColor string, err error) {
+	font, err := t.FontColor()
+	if err != nil {
+		return "", "", "", fmt.Errorf("invalid theme %q: %v", t.Name, err)
+	}
+	if t.Blink != nil {
+		blinkColor = t.Blink.String()
+	}
+	return t.Background.String(), string(font), blinkColor, nil
+}
+
+// ContrastRatio returns the contrast ratio of the font against the background
+func (t Theme) ContrastRatio() (float64, error) {
+	font, err := t.FontColor()
+	if err != nil {
+		return 0, err
+	}
+	c, _ := font.Color()
+	return ContrastRatio(t.Background, c), nil
+}
+
+// Warnings reports WCAG contrast failures of the theme; they do not stop a card being added
+func (t Theme) Warnings() []ValidationError {
+	bgColor, fontColor, _, err := t.Styling()
+	if err != nil {
+		return []ValidationError{{Field: "fontColor", Message: err.Error()}}
+	}
+	var v validator
+	v.checkContrast("", bgColor, fontColor)
+	return v.errors
+}
+
+// CheckContrast reports WCAG contrast failures between the bgColor and fontColor
+// attributes of each card data entry
+// Unlike Validate, these are warnings: Samsung Wallet accepts such cards, but text may
+// be hard to read; Validate and BuildStrict don't report them unless the builder has
+// SetStrictContrast enabled
+func CheckContrast(walletCard WalletCard) []ValidationError {
+	var v validator
+	for i, data := range walletCard.Card.Data {
+		bgColor, _ := data.Attributes["bgColor"].(string)
+		fontColor, _ := data.Attributes["fontColor"].(string)
+		v.checkContrast(fmt.Sprintf("card.data[%d].attributes", i), bgColor, fontColor)
+	}
+	return v.errors
+}
+
+// checkContrast adds a warning if fontColor fails WCAG AA contrast against bgColor
+// Unset or invalid colors are left to Validate
+func (v *validator) checkContrast(path, bgColor, fontColor string) {
+	background, err := ParseColor(bgColor)
+	if err != nil || fontColor == "" {
+		return
+	}
+	font, err := FontColor(fontColor).Color()
+	if err != nil {
+		return
+	}
+
+	field := "fontColor"
+	if path != "" {
+		field = attributePath(path, field)
+	}
+	switch ratio := ContrastRatio(background, font); {
+	case ratio < ContrastAALarge:
+		v.add(field, fmt.Sprintf("contrast %.2f:1 against bgColor %s fails WCAG AA (%.1f:1) even for large text; try %s",
+			ratio, background, ContrastAA, FontColorFor(background)))
+	case ratio < ContrastAA:
+		v.add(field, fmt.Sprintf("contrast %.2f:1 against bgColor %s fails WCAG AA (%.1f:1) for normal text",
+			ratio, background, ContrastAA))
+	}
+}
+
+// ThemeRegistry holds named themes; it is safe for concurrent use
+type ThemeRegistry struct {
+	mu     sync.RWMutex
+	themes map[string]Theme
+}
+
+// NewThemeRegistry creates a registry holding themes
+func NewThemeRegistry(themes ...Theme) (*ThemeRegistry, error) {
+	r := &ThemeRegistry{themes: make(map[string]Theme)}
+	for _, theme := range themes {
+		if err := r.Register(theme); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// Register adds or replaces a theme, checking that its colors are valid
+func (r *ThemeRegistry) Register(theme Theme) error {
+	if theme.Name == "" {
+		return fmt.Errorf("theme name is required")
+	}
+	if _, _, _, err := theme.Styling(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.themes[theme.Name] = theme
+	return nil
+}
+
+// Lookup returns the theme named name
+func (r *ThemeRegistry) Lookup(name string) (Theme, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	theme, ok := r.themes[name]
+	if !ok {
+		return Theme{}, fmt.Errorf("unknown theme: %q", name)
+	}
+	return theme, nil
+}
+
+// Names returns the registered theme names in sorted order
+func (r *ThemeRegistry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.themes))
+	for name := range r.themes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// stylingSetter is a card builder that takes bgColor, fontColor and blinkColor;
+// card builders implement it so ApplyTheme works with each of them
+type stylingSetter interface {
+	setStyling(bgColor, fontColor, blinkColor string, err error)
+}
+
+// ApplyTheme sets the colors of theme on builder; an invalid theme is reported by BuildStrict
+func ApplyTheme[B stylingSetter](builder B, theme Theme) B {
+	builder.setStyling(theme.Styling())
+	return builder
+}
+
+// setStyling implements stylingSetter
+func (b *EventTicketBuilder) setStyling(bgColor, fontColor, blinkColor string, err error) {
+	if err != nil {
+		b.errs = append(b.errs, err)
+		return
+	}
+	b.SetStyling(bgColor, fontColor, blinkColor)
+}
+
+// SetTheme sets the card colors from theme; an invalid theme is reported by BuildStrict
+// Contrast failures are only warnings, see CheckContrast and SetStrictContrast
+func (b *EventTicketBuilder) SetTheme(theme Theme) *EventTicketBuilder {
+	return ApplyTheme(b, theme)
+}
@@ -9,6 +9,16 @@ const (
 	CardTypeEventTicket CardType = "event_ticket"
 )
 
+// samsungType returns the Samsung Wallet card type used by the server APIs
+func (t CardType) samsungType() string {
+	switch t {
+	case CardTypeEventTicket:
+		return "ticket"
+	default:
+		return string(t)
+	}
+}
+
 // TicketSubType represents the subtype of event tickets according to Samsung Wallet API
 type TicketSubType string
 
@@ -25,17 +35,26 @@ type CardState string
 
 const (
 	CardStateAdded    CardState = "ADDED"
+	CardStateUpdated  CardState = "UPDATED"
 	CardStateDeleted  CardState = "DELETED"
 	CardStateCanceled CardState = "CANCELED"
+	CardStateExpired  CardState = "EXPIRED"
+	CardStateRedeemed CardState = "REDEEMED"
 )
 
 // Config holds the configuration for Samsung Wallet client
 type Config struct {
-	PartnerID         string `json:"partner_id"`          // Partner ID from Samsung Partners Portal
-	PartnerPrivateKey string `json:"partner_private_key"` // Partner's RSA private key for JWT signing
-	SamsungPublicKey  string `json:"samsung_public_key"`  // Samsung's public key for JWE encryption
-	CertificateID     string `json:"certificate_id"`      // 4 digit alphanumeric from Partners Portal
-	BaseURL           string `json:"base_url,omitempty"`  // Optional: Custom base URL
+	PartnerID         string        `json:"partner_id"`              // Partner ID from Samsung Partners Portal
+	PartnerPrivateKey string        `json:"partner_private_key"`     // Partner's RSA private key for JWT signing
+	SamsungPublicKey  string        `json:"samsung_public_key"`      // Samsung's public key for JWE encryption
+	CertificateID     string        `json:"certificate_id"`          // 4 digit alphanumeric from Partners Portal
+	Environment       Environment   `json:"environment,omitempty"`   // Optional: Production (default) or sandbox servers
+	BaseURL           string        `json:"base_url,omitempty"`      // Deprecated: Sets both APIBaseURL and LinkBaseURL
+	APIBaseURL        string        `json:"api_base_url,omitempty"`  // Optional: Server API base URL, required for sandbox
+	LinkBaseURL       string        `json:"link_base_url,omitempty"` // Optional: ATW link host (default https://a.swallet.link)
+	ATWVersion        string        `json:"atw_version,omitempty"`   // Optional: ATW link version path segment (default v3)
+	LinkFragment      string        `json:"link_fragment,omitempty"` // Optional: ATW link fragment name (default Clip)
+	Timeout           time.Duration `json:"timeout,omitempty"`       // Optional: HTTP client timeout (default 30s); per-call deadlines come from context
 }
 
 // Samsung Wallet Official API Card Structures
@@ -86,39 +105,42 @@ type TicketAttributes struct {
 	LogoImageLightURL string `json:"logoImage.lightUrl,omitempty"` // Logo image URL in light mode
 
 	// Optional common fields
-	Subtitle1         string `json:"subtitle1,omitempty"`         // Auxiliary field (max 32 chars)
-	Category          string `json:"category,omitempty"`          // Ticket category (max 16 chars) - deprecated
-	EventID           string `json:"eventId,omitempty"`           // Event identifier (max 32 chars)
-	GroupingID        string `json:"groupingId,omitempty"`        // Grouping identifier (max 32 chars)
-	OrderID           string `json:"orderId,omitempty"`           // Order identifier (max 32 chars)
-	WideImage         string `json:"wideImage,omitempty"`         // Wide horizontal image URL (max 256 kB)
-	ProviderViewLink  string `json:"providerViewLink,omitempty"`  // Link to additional provider info (max 512 chars)
-	Classification    string `json:"classification,omitempty"`    // ONETIME, REGULAR, or ANNUAL (default: ONETIME)
-	HolderName        string `json:"holderName,omitempty"`        // Name of card holder (max 64 chars)
-	IDPhotoData       string `json:"idPhoto.data,omitempty"`      // Holder's photo Base64 (max 20k)
-	IDPhotoFormat     string `json:"idPhoto.format,omitempty"`    // Image format (jpeg, png)
-	IDPhotoStatus     string `json:"idPhoto.status,omitempty"`    // Status (UNCHANGED)
-	Grade             string `json:"grade,omitempty"`             // Ticket grade (max 32 chars)
-	SeatClass         string `json:"seatClass,omitempty"`         // Seat class (max 32 chars)
-	Entrance          string `json:"entrance,omitempty"`          // Entrance gate (max 64 chars)
-	SeatNumber        string `json:"seatNumber,omitempty"`        // Seat location (max 256 chars)
-	SeatLayoutImage   string `json:"seatLayoutImage,omitempty"`   // Seat layout image URL (max 256 kB)
-	IssueDate         int64  `json:"issueDate,omitempty"`         // Issue date (epoch timestamp)
-	ReservationNumber string `json:"reservationNumber,omitempty"` // Reservation number (max 32 chars)
-	User              string `json:"user,omitempty"`              // User name (max 32 chars)
-	Certification     string `json:"certification,omitempty"`     // Certification (max 32 chars)
-	StartDate         int64  `json:"startDate,omitempty"`         // Event start date (epoch timestamp)
-	EndDate           int64  `json:"endDate,omitempty"`           // Event end date (epoch timestamp)
-	Person1           string `json:"person1,omitempty"`           // Person info JSON string (max 512 chars)
-	Locations         string `json:"locations,omitempty"`         // Locations JSON string (max 512 chars)
-	NoticeDesc        string `json:"noticeDesc,omitempty"`        // Notice description (max 1024 chars)
-	GroupInfo1        string `json:"groupInfo1,omitempty"`        // Group info 1 (max 32 chars)
-	GroupInfo2        string `json:"groupInfo2,omitempty"`        // Group info 2 (max 32 chars)
-	GroupInfo3        string `json:"groupInfo3,omitempty"`        // Group info 3 (max 32 chars)
-	CSInfo            string `json:"csInfo,omitempty"`            // Customer service info JSON string (max 512 chars)
-	AppLinkName       string `json:"appLinkName,omitempty"`       // App link name (max 32 chars)
-	AppLinkLogo       string `json:"appLinkLogo,omitempty"`       // App link logo URL (max 256 kB)
-	AppLinkData       string `json:"appLinkData,omitempty"`       // App link data (max 512 chars)
+	Subtitle1          string `json:"subtitle1,omitempty"`           // Auxiliary field (max 32 chars)
+	Category           string `json:"category,omitempty"`            // Ticket category (max 16 chars) - deprecated
+	EventID            string `json:"eventId,omitempty"`             // Event identifier (max 32 chars)
+	GroupingID         string `json:"groupingId,omitempty"`          // Grouping identifier (max 32 chars)
+	OrderID            string `json:"orderId,omitempty"`             // Order identifier (max 32 chars)
+	WideImage          string `json:"wideImage,omitempty"`           // Wide horizontal image URL (max 256 kB)
+	ProviderViewLink   string `json:"providerViewLink,omitempty"`    // Link to additional provider info (max 512 chars)
+	Classification     string `json:"classification,omitempty"`      // ONETIME, REGULAR, or ANNUAL (default: ONETIME)
+	HolderName         string `json:"holderName,omitempty"`          // Name of card holder (max 64 chars)
+	IDPhotoData        string `json:"idPhoto.data,omitempty"`        // Holder's photo Base64 (max 20k)
+	IDPhotoFormat      string `json:"idPhoto.format,omitempty"`      // Image format (jpeg, png)
+	IDPhotoStatus      string `json:"idPhoto.status,omitempty"`      // Status (UNCHANGED)
+	Grade              string `json:"grade,omitempty"`               // Ticket grade (max 32 chars)
+	SeatClass          string `json:"seatClass,omitempty"`           // Seat class (max 32 chars)
+	Entrance           string `json:"entrance,omitempty"`            // Entrance gate (max 64 chars)
+	SeatNumber         string `json:"seatNumber,omitempty"`          // Seat location (max 256 chars)
+	SeatLayoutImage    string `json:"seatLayoutImage,omitempty"`     // Seat layout image URL (max 256 kB)
+	IssueDate          int64  `json:"issueDate,omitempty"`           // Issue date (epoch timestamp)
+	IssueDateUTCOffset string `json:"issueDate.utcOffset,omitempty"` // Issue date UTC offset (e.g. UTC+09:00)
+	ReservationNumber  string `json:"reservationNumber,omitempty"`   // Reservation number (max 32 chars)
+	User               string `json:"user,omitempty"`                // User name (max 32 chars)
+	Certification      string `json:"certification,omitempty"`       // Certification (max 32 chars)
+	StartDate          int64  `json:"startDate,omitempty"`           // Event start date (epoch timestamp)
+	StartDateUTCOffset string `json:"startDate.utcOffset,omitempty"` // Event start date UTC offset (e.g. UTC+09:00)
+	EndDate            int64  `json:"endDate,omitempty"`             // Event end date (epoch timestamp)
+	EndDateUTCOffset   string `json:"endDate.utcOffset,omitempty"`   // Event end date UTC offset (e.g. UTC+09:00)
+	Person1            string `json:"person1,omitempty"`             // Person info JSON string (max 512 chars)
+	Locations          string `json:"locations,omitempty"`           // Locations JSON string (max 512 chars)
+	NoticeDesc         string `json:"noticeDesc,omitempty"`          // Notice description (max 1024 chars)
+	GroupInfo1         string `json:"groupInfo1,omitempty"`          // Group info 1 (max 32 chars)
+	GroupInfo2         string `json:"groupInfo2,omitempty"`          // Group info 2 (max 32 chars)
+	GroupInfo3         string `json:"groupInfo3,omitempty"`          // Group info 3 (max 32 chars)
+	CSInfo             string `json:"csInfo,omitempty"`              // Customer service info JSON string (max 512 chars)
+	AppLinkName        string `json:"appLinkName,omitempty"`         // App link name (max 32 chars)
+	AppLinkLogo        string `json:"appLinkLogo,omitempty"`         // App link logo URL (max 256 kB)
+	AppLinkData        string `json:"appLinkData,omitempty"`         // App link data (max 512 chars)
 
 	// Styling fields
 	BGColor    string `json:"bgColor,omitempty"`    // Background color
@@ -138,39 +160,42 @@ type TicketAttributes struct {
 	ProvisionInterval string `json:"provision.interval,omitempty"` // Provisioning interval
 
 	// Related coupon fields (i: 1~3)
-	RelCoupon1Title                string `json:"relCoupon1.title,omitempty"`                // Related coupon 1 title
-	RelCoupon1Subtitle             string `json:"relCoupon1.subtitle,omitempty"`             // Related coupon 1 subtitle
-	RelCoupon1ProviderName         string `json:"relCoupon1.providerName,omitempty"`         // Related coupon 1 provider name
-	RelCoupon1ImageFileSrc         string `json:"relCoupon1.imageFileSrc,omitempty"`         // Related coupon 1 image URL
-	RelCoupon1NoticeDescription    string `json:"relCoupon1.noticeDescription,omitempty"`    // Related coupon 1 notice
-	RelCoupon1NotificationTime     int64  `json:"relCoupon1.notificationTime,omitempty"`     // Related coupon 1 notification time
-	RelCoupon1Value                string `json:"relCoupon1.value,omitempty"`                // Related coupon 1 value
-	RelCoupon1SerialType           string `json:"relCoupon1.serialType,omitempty"`           // Related coupon 1 serial type
-	RelCoupon1PTFormat             string `json:"relCoupon1.ptFormat,omitempty"`             // Related coupon 1 PT format
-	RelCoupon1PTSubFormat          string `json:"relCoupon1.ptSubFormat,omitempty"`          // Related coupon 1 PT sub-format
-	RelCoupon1ErrorCorrectionLevel string `json:"relCoupon1.errorCorrectionLevel,omitempty"` // Related coupon 1 error correction
-	RelCoupon2Title                string `json:"relCoupon2.title,omitempty"`                // Related coupon 2 title
-	RelCoupon2Subtitle             string `json:"relCoupon2.subtitle,omitempty"`             // Related coupon 2 subtitle
-	RelCoupon2ProviderName         string `json:"relCoupon2.providerName,omitempty"`         // Related coupon 2 provider name
-	RelCoupon2ImageFileSrc         string `json:"relCoupon2.imageFileSrc,omitempty"`         // Related coupon 2 image URL
-	RelCoupon2NoticeDescription    string `json:"relCoupon2.noticeDescription,omitempty"`    // Related coupon 2 notice
-	RelCoupon2NotificationTime     int64  `json:"relCoupon2.notificationTime,omitempty"`     // Related coupon 2 notification time
-	RelCoupon2Value                string `json:"relCoupon2.value,omitempty"`                // Related coupon 2 value
-	RelCoupon2SerialType           string `json:"relCoupon2.serialType,omitempty"`           // Related coupon 2 serial type
-	RelCoupon2PTFormat             string `json:"relCoupon2.ptFormat,omitempty"`             // Related coupon 2 PT format
-	RelCoupon2PTSubFormat          string `json:"relCoupon2.ptSubFormat,omitempty"`          // Related coupon 2 PT sub-format
-	RelCoupon2ErrorCorrectionLevel string `json:"relCoupon2.errorCorrectionLevel,omitempty"` // Related coupon 2 error correction
-	RelCoupon3Title                string `json:"relCoupon3.title,omitempty"`                // Related coupon 3 title
-	RelCoupon3Subtitle             string `json:"relCoupon3.subtitle,omitempty"`             // Related coupon 3 subtitle
-	RelCoupon3ProviderName         string `json:"relCoupon3.providerName,omitempty"`         // Related coupon 3 provider name
-	RelCoupon3ImageFileSrc         string `json:"relCoupon3.imageFileSrc,omitempty"`         // Related coupon 3 image URL
-	RelCoupon3NoticeDescription    string `json:"relCoupon3.noticeDescription,omitempty"`    // Related coupon 3 notice
-	RelCoupon3NotificationTime     int64  `json:"relCoupon3.notificationTime,omitempty"`     // Related coupon 3 notification time
-	RelCoupon3Value                string `json:"relCoupon3.value,omitempty"`                // Related coupon 3 value
-	RelCoupon3SerialType           string `json:"relCoupon3.serialType,omitempty"`           // Related coupon 3 serial type
-	RelCoupon3PTFormat             string `json:"relCoupon3.ptFormat,omitempty"`             // Related coupon 3 PT format
-	RelCoupon3PTSubFormat          string `json:"relCoupon3.ptSubFormat,omitempty"`          // Related coupon 3 PT sub-format
-	RelCoupon3ErrorCorrectionLevel string `json:"relCoupon3.errorCorrectionLevel,omitempty"` // Related coupon 3 error correction
+	RelCoupon1Title                     string `json:"relCoupon1.title,omitempty"`                      // Related coupon 1 title
+	RelCoupon1Subtitle                  string `json:"relCoupon1.subtitle,omitempty"`                   // Related coupon 1 subtitle
+	RelCoupon1ProviderName              string `json:"relCoupon1.providerName,omitempty"`               // Related coupon 1 provider name
+	RelCoupon1ImageFileSrc              string `json:"relCoupon1.imageFileSrc,omitempty"`               // Related coupon 1 image URL
+	RelCoupon1NoticeDescription         string `json:"relCoupon1.noticeDescription,omitempty"`          // Related coupon 1 notice
+	RelCoupon1NotificationTime          int64  `json:"relCoupon1.notificationTime,omitempty"`           // Related coupon 1 notification time
+	RelCoupon1NotificationTimeUTCOffset string `json:"relCoupon1.notificationTime.utcOffset,omitempty"` // Related coupon 1 notification time UTC offset
+	RelCoupon1Value                     string `json:"relCoupon1.value,omitempty"`                      // Related coupon 1 value
+	RelCoupon1SerialType                string `json:"relCoupon1.serialType,omitempty"`                 // Related coupon 1 serial type
+	RelCoupon1PTFormat                  string `json:"relCoupon1.ptFormat,omitempty"`                   // Related coupon 1 PT format
+	RelCoupon1PTSubFormat               string `json:"relCoupon1.ptSubFormat,omitempty"`                // Related coupon 1 PT sub-format
+	RelCoupon1ErrorCorrectionLevel      string `json:"relCoupon1.errorCorrectionLevel,omitempty"`       // Related coupon 1 error correction
+	RelCoupon2Title                     string `json:"relCoupon2.title,omitempty"`                      // Related coupon 2 title
+	RelCoupon2Subtitle                  string `json:"relCoupon2.subtitle,omitempty"`                   // Related coupon 2 subtitle
+	RelCoupon2ProviderName              string `json:"relCoupon2.providerName,omitempty"`               // Related coupon 2 provider name
+	RelCoupon2ImageFileSrc              string `json:"relCoupon2.imageFileSrc,omitempty"`               // Related coupon 2 image URL
+	RelCoupon2NoticeDescription         string `json:"relCoupon2.noticeDescription,omitempty"`          // Related coupon 2 notice
+	RelCoupon2NotificationTime          int64  `json:"relCoupon2.notificationTime,omitempty"`           // Related coupon 2 notification time
+	RelCoupon2NotificationTimeUTCOffset string `json:"relCoupon2.notificationTime.utcOffset,omitempty"` // Related coupon 2 notification time UTC offset
+	RelCoupon2Value                     string `json:"relCoupon2.value,omitempty"`                      // Related coupon 2 value
+	RelCoupon2SerialType                string `json:"relCoupon2.serialType,omitempty"`                 // Related coupon 2 serial type
+	RelCoupon2PTFormat                  string `json:"relCoupon2.ptFormat,omitempty"`                   // Related coupon 2 PT format
+	RelCoupon2PTSubFormat               string `json:"relCoupon2.ptSubFormat,omitempty"`                // Related coupon 2 PT sub-format
+	RelCoupon2ErrorCorrectionLevel      string `json:"relCoupon2.errorCorrectionLevel,omitempty"`       // Related coupon 2 error correction
+	RelCoupon3Title                     string `json:"relCoupon3.title,omitempty"`                      // Related coupon 3 title
+	RelCoupon3Subtitle                  string `json:"relCoupon3.subtitle,omitempty"`                   // Related coupon 3 subtitle
+	RelCoupon3ProviderName              string `json:"relCoupon3.providerName,omitempty"`               // Related coupon 3 provider name
+	RelCoupon3ImageFileSrc              string `json:"relCoupon3.imageFileSrc,omitempty"`               // Related coupon 3 image URL
+	RelCoupon3NoticeDescription         string `json:"relCoupon3.noticeDescription,omitempty"`          // Related coupon 3 notice
+	RelCoupon3NotificationTime          int64  `json:"relCoupon3.notificationTime,omitempty"`           // Related coupon 3 notification time
+	RelCoupon3NotificationTimeUTCOffset string `json:"relCoupon3.notificationTime.utcOffset,omitempty"` // Related coupon 3 notification time UTC offset
+	RelCoupon3Value                     string `json:"relCoupon3.value,omitempty"`                      // Related coupon 3 value
+	RelCoupon3SerialType                string `json:"relCoupon3.serialType,omitempty"`                 // Related coupon 3 serial type
+	RelCoupon3PTFormat                  string `json:"relCoupon3.ptFormat,omitempty"`                   // Related coupon 3 PT format
+	RelCoupon3PTSubFormat               string `json:"relCoupon3.ptSubFormat,omitempty"`                // Related coupon 3 PT sub-format
+	RelCoupon3ErrorCorrectionLevel      string `json:"relCoupon3.errorCorrectionLevel,omitempty"`       // Related coupon 3 error correction
 }
 
 // WalletCardAttributes represents generic attributes that can be used for any card type
@@ -251,6 +276,8 @@ type CardStateCallback struct {
 }
 
 // UpdateCardRequest represents a request to update a card
+//
+// Deprecated: UpdateCard sends an UpdateNotificationRequest instead.
 type UpdateCardRequest struct {
 	PartnerID string   `json:"partner_id"` // Changed from service_id
 	CardID    string   `json:"card_id"`
@@ -258,19 +285,10 @@ type UpdateCardRequest struct {
 }
 
 // CancelCardRequest represents a request to cancel cards
+//
+// Deprecated: CancelCard no longer sends requests. Use CancelNotificationRequest.
 type CancelCardRequest struct {
 	PartnerID string `json:"partner_id"` // Changed from service_id
 	EventID   string `json:"event_id"`
 	Reason    string `json:"reason,omitempty"`
 }
-
-// APIError represents an error response from the Samsung Wallet API
-type APIError struct {
-	Code    string `json:"code"`
-	Message string `json:"message"`
-	Details string `json:"details,omitempty"`
-}
-
-func (e *APIError) Error() string {
-	return e.Message
-}
@@ -0,0 +1,443 @@
+package wallet
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ValidationError describes one field of a WalletCard that Samsung would reject
+type ValidationError struct {
+	Field   string // JSON path of the field, e.g. card.data[0].attributes["barcode.value"]
+	Message string // What is wrong with the field
+}
+
+// Error implements error
+func (e ValidationError) Error() string {
+	return e.Field + ": " + e.Message
+}
+
+// Card types known to Samsung Wallet
+var knownCardTypes = map[string]bool{
+	"boardingpass": true,
+	"ticket":       true,
+	"coupon":       true,
+	"giftcard":     true,
+	"loyalty":      true,
+	"idcard":       true,
+	"payasyougo":   true,
+	"generic":      true,
+}
+
+// Ticket subtypes accepted by Samsung Wallet
+var ticketSubTypes = map[string]bool{
+	string(TicketSubTypePerformances): true,
+	string(TicketSubTypeSports):       true,
+	string(TicketSubTypeMovies):       true,
+	string(TicketSubTypeEntrances):    true,
+	string(TicketSubTypeOthers):       true,
+}
+
+// ticketScheduledSubTypes are ticket subtypes for events held at a set time, which require startDate
+var ticketScheduledSubTypes = map[string]bool{
+	string(TicketSubTypePerformances): true,
+	string(TicketSubTypeSports):       true,
+	string(TicketSubTypeMovies):       true,
+}
+
+const (
+	// maxRefIDLength is the maximum length of a refId
+	maxRefIDLength = 32
+
+	// Epoch millisecond range accepted for dates, 2000-01-01 to 2100-01-01 UTC
+	minEpochMillis = 946684800000
+	maxEpochMillis = 4102444800000
+)
+
+// attributeKind selects how an attribute value is checked
+type attributeKind int
+
+const (
+	kindText      attributeKind = iota // Plain or HTML text
+	kindURL                            // Absolute http(s) URL
+	kindEpoch                          // Epoch milliseconds
+	kindColor                          // #RRGGBB
+	kindFontColor                      // light, dark or #RRGGBB
+	kindJSON                           // Text holding a JSON document
+	kindEnum                           // One of a fixed set of values
+	kindUTCOffset                      // UTC offset such as UTC+09:00
+	kindSeconds                        // Positive whole number of seconds as text
+)
+
+// attributeRule describes the constraints of one card attribute
+type attributeRule struct {
+	key      string
+	kind     attributeKind
+	maxLen   int      // Maximum length in characters; 0 means no limit
+	required bool     // Required in the card's attributes
+	values   []string // Allowed values of kindEnum attributes
+}
+
+// Enumerated attribute values
+var (
+	classificationValues  = []string{"ONETIME", "REGULAR", "ANNUAL"}
+	serialTypeValues      = []string{"QRCODE", "BARCODE", "SERIALNUMBER"}
+	ptFormatValues        = []string{"QRCODE", "QRCODESERIAL", "BARCODE", "BARCODESERIAL", "SERIAL"}
+	ptSubFormatValues     = []string{"QR_CODE", "AZTEC", "DATA_MATRIX", "PDF_417", "CODE_128", "CODE_39", "CODABAR", "EAN_8", "EAN_13", "ITF", "UPC_A", "UPC_E"}
+	errorCorrectionValues = []string{"L", "M", "Q", "H"}
+	idPhotoFormatValues   = []string{"jpeg", "png"}
+	idPhotoStatusValues   = []string{"UNCHANGED"}
+)
+
+// ticketAttributeRules lists the ticket attribute constraints, following TicketAttributes
+var ticketAttributeRules = append([]attributeRule{
+	{key: "title", kind: kindText, maxLen: 32, required: true},
+	{key: "mainImg", kind: kindURL, required: true},
+	{key: "logoImage", kind: kindURL, required: true},
+	{key: "providerName", kind: kindText, maxLen: 32, required: true},
+	{key: "logoImage.darkUrl", kind: kindURL},
+	{key: "logoImage.lightUrl", kind: kindURL},
+	{key: "subtitle1", kind: kindText, maxLen: 32},
+	{key: "category", kind: kindText, maxLen: 16},
+	{key: "eventId", kind: kindText, maxLen: 32},
+	{key: "groupingId", kind: kindText, maxLen: 32},
+	{key: "orderId", kind: kindText, maxLen: 32},
+	{key: "wideImage", kind: kindURL},
+	{key: "providerViewLink", kind: kindURL, maxLen: 512},
+	{key: "classification", kind: kindEnum, values: classificationValues},
+	{key: "holderName", kind: kindText, maxLen: 64},
+	{key: "idPhoto.data", kind: kindText, maxLen: 20000},
+	{key: "idPhoto.format", kind: kindEnum, values: idPhotoFormatValues},
+	{key: "idPhoto.status", kind: kindEnum, values: idPhotoStatusValues},
+	{key: "grade", kind: kindText, maxLen: 32},
+	{key: "seatClass", kind: kindText, maxLen: 32},
+	{key: "entrance", kind: kindText, maxLen: 64},
+	{key: "seatNumber", kind: kindText, maxLen: 256},
+	{key: "seatLayoutImage", kind: kindURL},
+	{key: "issueDate", kind: kindEpoch},
+	{key: "issueDate.utcOffset", kind: kindUTCOffset},
+	{key: "reservationNumber", kind: kindText, maxLen: 32},
+	{key: "user", kind: kindText, maxLen: 32},
+	{key: "certification", kind: kindText, maxLen: 32},
+	{key: "startDate", kind: kindEpoch},
+	{key: "startDate.utcOffset", kind: kindUTCOffset},
+	{key: "endDate", kind: kindEpoch},
+	{key: "endDate.utcOffset", kind: kindUTCOffset},
+	{key: "person1", kind: kindJSON, maxLen: 512},
+	{key: "locations", kind: kindJSON, maxLen: 512},
+	{key: "noticeDesc", kind: kindText, maxLen: 1024},
+	{key: "groupInfo1", kind: kindText, maxLen: 32},
+	{key: "groupInfo2", kind: kindText, maxLen: 32},
+	{key: "groupInfo3", kind: kindText, maxLen: 32},
+	{key: "csInfo", kind: kindJSON, maxLen: 512},
+	{key: "appLinkName", kind: kindText, maxLen: 32},
+	{key: "appLinkLogo", kind: kindURL},
+	{key: "appLinkData", kind: kindText, maxLen: 512},
+	{key: "bgColor", kind: kindColor},
+	{key: "fontColor", kind: kindFontColor},
+	{key: "blinkColor", kind: kindColor},
+	{key: "barcode.value", kind: kindText, maxLen: 4096},
+	{key: "barcode.serialType", kind: kindEnum, values: serialTypeValues},
+	{key: "barcode.ptFormat", kind: kindEnum, values: ptFormatValues},
+	{key: "barcode.ptSubFormat", kind: kindEnum, values: ptSubFormatValues},
+	{key: "barcode.errorCorrectionLevel", kind: kindEnum, values: errorCorrectionValues},
+	{key: "barcode.interval", kind: kindSeconds},
+	{key: "provision.data", kind: kindText, maxLen: 512},
+	{key: "provision.interval", kind: kindSeconds},
+}, relatedCouponRules()...)
+
+// relatedCouponRules returns the constraints of the relCoupon1-3 attributes
+func relatedCouponRules() []attributeRule {
+	var rules []attributeRule
+	for i := 1; i <= 3; i++ {
+		prefix := "relCoupon" + strconv.Itoa(i) + "."
+		rules = append(rules,
+			attributeRule{key: prefix + "title", kind: kindText, maxLen: 32},
+			attributeRule{key: prefix + "subtitle", kind: kindText, maxLen: 32},
+			attributeRule{key: prefix + "providerName", kind: kindText, maxLen: 32},
+			attributeRule{key: prefix + "imageFileSrc", kind: kindURL},
+			attributeRule{key: prefix + "noticeDescription", kind: kindText, maxLen: 1024},
+			attributeRule{key: prefix + "notificationTime", kind: kindEpoch},
+			attributeRule{key: prefix + "notificationTime.utcOffset", kind: kindUTCOffset},
+			attributeRule{key: prefix + "value", kind: kindText, maxLen: 4096},
+			attributeRule{key: prefix + "serialType", kind: kindEnum, values: serialTypeValues},
+			attributeRule{key: prefix + "ptFormat", kind: kindEnum, values: ptFormatValues},
+			attributeRule{key: prefix + "ptSubFormat", kind: kindEnum, values: ptSubFormatValues},
+			attributeRule{key: prefix + "errorCorrectionLevel", kind: kindEnum, values: errorCorrectionValues},
+		)
+	}
+	return rules
+}
+
+// hexColorPattern matches #RRGGBB colors
+var hexColorPattern = regexp.MustCompile(`^#[0-9A-Fa-f]{6}$`)
+
+// Validate checks a WalletCard against the Samsung Wallet field constraints:
+// required fields per card type and subtype, length limits, enumerated values,
+// epoch date ranges and color formats
+// Attributes are only checked for ticket cards; for other card types only the card
+// envelope is checked, so use ValidatesAttributes to tell whether nil means fully valid
+// It returns nil when the card is valid
+func Validate(walletCard WalletCard) []ValidationError {
+	v := &validator{}
+	card := walletCard.Card
+
+	switch {
+	case card.Type == "":
+		v.add("card.type", "is required")
+	case !knownCardTypes[card.Type]:
+		v.add("card.type", fmt.Sprintf("unknown card type %q", card.Type))
+	}
+
+	if card.Type == "ticket" {
+		switch {
+		case card.SubType == "":
+			v.add("card.subType", "is required")
+		case !ticketSubTypes[card.SubType]:
+			v.add("card.subType", fmt.Sprintf("unknown ticket subtype %q", card.SubType))
+		}
+	}
+
+	if len(card.Data) == 0 {
+		v.add("card.data", "must contain at least one entry")
+	}
+
+	for i, data := range card.Data {
+		path := fmt.Sprintf("card.data[%d]", i)
+
+		switch length := utf8.RuneCountInString(data.RefID); {
+		case length == 0:
+			v.add(path+".refId", "is required")
+		case length > maxRefIDLength:
+			v.add(path+".refId", fmt.Sprintf("must be at most %d characters, got %d", maxRefIDLength, length))
+		}
+		v.checkEpoch(path+".createdAt", data.CreatedAt)
+		v.checkEpoch(path+".updatedAt", data.UpdatedAt)
+		v.checkLanguage(path+".language", data.Language)
+
+		if !ValidatesAttributes(card.Type) {
+			continue
+		}
+
+		v.checkAttributes(path+".attributes", data.Attributes, true)
+		v.checkBarcode(path+".attributes", data.Attributes)
+		if ticketScheduledSubTypes[card.SubType] && data.Attributes["startDate"] == nil {
+			v.add(attributePath(path+".attributes", "startDate"), fmt.Sprintf("is required for %s tickets", card.SubType))
+		}
+		start, hasStart := epochMillis(data.Attributes["startDate"])
+		end, hasEnd := epochMillis(data.Attributes["endDate"])
+		if hasStart && hasEnd && end < start {
+			v.add(attributePath(path+".attributes", "endDate"), "must not be before startDate")
+		}
+
+		for j, localization := range data.Localization {
+			locPath := fmt.Sprintf("%s.localization[%d]", path, j)
+			v.checkLanguage(locPath+".language", localization.Language)
+			v.checkAttributes(locPath+".attributes", localization.Attributes, false)
+			v.checkLocalizedKeys(locPath+".attributes", localization.Attributes, data.Attributes)
+		}
+	}
+
+	return v.errors
+}
+
+// ValidatesAttributes reports whether Validate checks the attributes of cards of cardType
+// Only ticket attributes are checked so far
+func ValidatesAttributes(cardType string) bool {
+	return cardType == "ticket"
+}
+
+// validator accumulates validation errors
+type validator struct {
+	errors []ValidationError
+}
+
+// add records a validation error
+func (v *validator) add(field, message string) {
+	v.errors = append(v.errors, ValidationError{Field: field, Message: message})
+}
+
+// checkAttributes checks attributes against the ticket rules; required fields are
+// only enforced for base attributes, since localizations override a subset
+func (v *validator) checkAttributes(path string, attributes map[string]interface{}, enforceRequired bool) {
+	for _, rule := range ticketAttributeRules {
+		field := attributePath(path, rule.key)
+
+		value, ok := attributes[rule.key]
+		if !ok || value == nil || value == "" {
+			if rule.required && enforceRequired {
+				v.add(field, "is required")
+			}
+			continue
+		}
+
+		if rule.kind == kindEpoch {
+			ms, ok := epochMillis(value)
+			if !ok {
+				v.add(field, fmt.Sprintf("must be epoch milliseconds, got %T", value))
+				continue
+			}
+			v.checkEpoch(field, ms)
+			continue
+		}
+
+		s, ok := value.(string)
+		if !ok {
+			v.add(field, fmt.Sprintf("must be a string, got %T", value))
+			continue
+		}
+		if length := utf8.RuneCountInString(s); rule.maxLen > 0 && length > rule.maxLen {
+			v.add(field, fmt.Sprintf("must be at most %d characters, got %d", rule.maxLen, length))
+		}
+
+		switch rule.kind {
+		case kindURL:
+			if u, err := url.Parse(s); err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+				v.add(field, "must be an absolute http(s) URL")
+			}
+		case kindColor:
+			if !hexColorPattern.MatchString(s) {
+				v.add(field, fmt.Sprintf("must be a #RRGGBB color, got %q", s))
+			}
+		case kindFontColor:
+			if s != "light" && s != "dark" && !hexColorPattern.MatchString(s) {
+				v.add(field, fmt.Sprintf("must be light, dark or a #RRGGBB color, got %q", s))
+			}
+		case kindJSON:
+			if !json.Valid([]byte(s)) {
+				v.add(field, "must be valid JSON")
+			}
+		case kindEnum:
+			if !containsString(rule.values, s) {
+				v.add(field, fmt.Sprintf("must be one of %s, got %q", strings.Join(rule.values, ", "), s))
+			}
+		case kindSeconds:
+			if n, err := strconv.Atoi(s); err != nil || n <= 0 {
+				v.add(field, fmt.Sprintf("must be a positive number of seconds, got %q", s))
+			}
+		case kindUTCOffset:
+			if _, err := ParseUTCOffset(s); err != nil {
+				v.add(field, fmt.Sprintf("must be a UTC offset such as UTC+09:00, got %q", s))
+			}
+			if date := strings.TrimSuffix(rule.key, utcOffsetSuffix); enforceRequired && attributes[date] == nil {
+				v.add(field, fmt.Sprintf("is set without %s", date))
+			}
+		}
+	}
+}
+
+// checkBarcode checks the barcode value against its symbology, and that the other
+// barcode attributes are not set without a value
+func (v *validator) checkBarcode(path string, attributes map[string]interface{}) {
+	if spec, ok := barcodeSpecFromAttributes(attributes); ok {
+		if err := spec.Validate(); err != nil {
+			v.add(attributePath(path, "barcode.value"), err.Error())
+		}
+		return
+	}
+	if value, _ := attributes["barcode.value"].(string); value != "" {
+		return
+	}
+	for _, key := range []string{"barcode.serialType", "barcode.ptFormat", "barcode.ptSubFormat"} {
+		if value, _ := attributes[key].(string); value != "" {
+			v.add(attributePath(path, "barcode.value"), fmt.Sprintf("is required when %s is set", key))
+			return
+		}
+	}
+}
+
+// checkLanguage checks that language is a BCP 47 language tag
+func (v *validator) checkLanguage(field, language string) {
+	if language == "" {
+		v.add(field, "is required")
+		return
+	}
+	if err := ValidateLanguageTag(language); err != nil {
+		v.add(field, err.Error())
+	}
+}
+
+// checkLocalizedKeys checks that every localized key is a known ticket attribute set
+// in the base attributes, which catches typos such as "tittle"
+func (v *validator) checkLocalizedKeys(path string, localized, base map[string]interface{}) {
+	keys := make([]string, 0, len(localized))
+	for key := range localized {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		switch {
+		case !isTicketAttribute(key):
+			v.add(attributePath(path, key), "is not a ticket attribute")
+		case base[key] == nil || base[key] == "":
+			v.add(attributePath(path, key), "is not set in the base attributes")
+		}
+	}
+}
+
+// isTicketAttribute reports whether key is a known ticket attribute
+func isTicketAttribute(key string) bool {
+	for _, rule := range ticketAttributeRules {
+		if rule.key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// checkEpoch checks that ms is a plausible epoch millisecond timestamp
+func (v *validator) checkEpoch(field string, ms int64) {
+	switch {
+	case ms == 0:
+		v.add(field, "is required")
+	case ms > 0 && ms < minEpochMillis/1000*10:
+		// Values this small are almost always epoch seconds
+		v.add(field, fmt.Sprintf("must be epoch milliseconds, got %d (seconds?)", ms))
+	case ms < minEpochMillis || ms > maxEpochMillis:
+		v.add(field, fmt.Sprintf("must be between %s and %s, got %d",
+			time.UnixMilli(minEpochMillis).UTC().Format(time.DateOnly),
+			time.UnixMilli(maxEpochMillis).UTC().Format(time.DateOnly), ms))
+	}
+}
+
+// epochMillis converts a decoded attribute value to epoch milliseconds
+func epochMillis(value interface{}) (int64, bool) {
+	switch n := value.(type) {
+	case int64:
+		return n, true
+	case int:
+		return int64(n), true
+	case float64:
+		return int64(n), n == float64(int64(n))
+	case json.Number:
+		ms, err := n.Int64()
+		return ms, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// attributePath returns the JSON path of an attribute; keys containing dots use bracket notation
+func attributePath(path, key string) string {
+	if strings.Contains(key, ".") {
+		return path + `["` + key + `"]`
+	}
+	return path + "." + key
+}
+
+// containsString reports whether values contains s
+func containsString(values []string, s string) bool {
+	for _, value := range values {
+		if value == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,93 @@
+package wallet
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTicketAttributeRulesCoverTicketAttributes(t *testing.T) {
+	typ := reflect.TypeOf(TicketAttributes{})
+	for i := 0; i < typ.NumField(); i++ {
+		key, _, _ := strings.Cut(typ.Field(i).Tag.Get("json"), ",")
+		if !isTicketAttribute(key) {
+			t.Errorf("TicketAttributes.%s (%s) has no validation rule", typ.Field(i).Name, key)
+		}
+	}
+}
+
+func TestValidateAttributeRules(t *testing.T) {
+	tests := map[string]struct {
+		key   string
+		value interface{}
+	}{
+		"long coupon title":   {"relCoupon2.title", strings.Repeat("x", 33)},
+		"long coupon value":   {"relCoupon1.value", strings.Repeat("x", 4097)},
+		"zero interval":       {"barcode.interval", "0"},
+		"fractional interval": {"provision.interval", "1.5"},
+		"coupon image":        {"relCoupon3.imageFileSrc", "coupon.png"},
+	}
+	for name, tt := range tests {
+		card := validTicket()
+		card.Card.Data[0].Attributes[tt.key] = tt.value
+
+		errs := Validate(card)
+		if len(errs) != 1 || errs[0].Field != `card.data[0].attributes["`+tt.key+`"]` {
+			t.Errorf("%s: Validate() = %v, want one error for %s", name, errs, tt.key)
+		}
+	}
+
+	card := validTicket()
+	card.Card.Data[0].Attributes["barcode.interval"] = "30"
+	card.Card.Data[0].Attributes["relCoupon1.title"] = "Free drink"
+	if errs := Validate(card); errs != nil {
+		t.Errorf("Validate() = %v, want no errors", errs)
+	}
+}
+
+func TestValidateOtherCardTypesChecksEnvelopeOnly(t *testing.T) {
+	card := validTicket()
+	card.Card.Type = "coupon"
+	card.Card.SubType = ""
+	card.Card.Data[0].Attributes = WalletCardAttributes{"title": strings.Repeat("x", 100)}
+
+	if errs := Validate(card); errs != nil {
+		t.Errorf("Validate() = %v, want no errors", errs)
+	}
+	if ValidatesAttributes("coupon") || !ValidatesAttributes("ticket") {
+		t.Error("ValidatesAttributes() should only hold for ticket")
+	}
+
+	card.Card.Data[0].RefID = ""
+	if errs := Validate(card); len(errs) != 1 || errs[0].Field != "card.data[0].refId" {
+		t.Errorf("Validate() = %v, want a refId error", errs)
+	}
+}
+
+// validTicket returns a minimal ticket that passes Validate
+func validTicket() WalletCard {
+	return WalletCard{Card: WalletCardBody{
+		Type:    "ticket",
+		SubType: string(TicketSubTypeOthers),
+		Data: []WalletCardData{{
+			RefID:     "ticket-001",
+			CreatedAt: 1700000000000,
+			UpdatedAt: 1700000000000,
+			Language:  "en",
+			Attributes: WalletCardAttributes{
+				"title":        "Concert",
+				"mainImg":      "https://example.com/main.png",
+				"logoImage":    "https://example.com/logo.png",
+				"providerName": "Live Nation",
+			},
+		}},
+	}}
+}
+
+// newTestTicket returns a builder for a ticket that passes Validate
+func newTestTicket() *EventTicketBuilder {
+	return NewEventTicket("ticket-001", "Concert").
+		SetMainImage("https://example.com/main.png").
+		SetLogoImage("https://example.com/logo.png").
+		SetProviderName("Live Nation")
+}
@@ -0,0 +1,135 @@
+package wallettest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-jose/go-jose/v3"
+
+	"github.com/abyssparanoia/samsung-wallet-go/wallet"
+)
+
+// Calls made by Samsung Wallet to the partner server
+
+// FetchCardData calls the partner's Get Card Data endpoint the way Samsung Wallet
+// does after a data fetch link is opened or an update notification is received
+//
+// The request is GET {partnerBaseURL}/cards/{cardId}/{refId}, authenticated with
+// a token signed by the fake server's Samsung key.
+func (s *Server) FetchCardData(ctx context.Context, partnerBaseURL, cardID, refID string) (*wallet.WalletCard, error) {
+	path := fmt.Sprintf("/cards/%s/%s", url.PathEscape(cardID), url.PathEscape(refID))
+
+	body, err := s.callPartner(ctx, http.MethodGet, partnerBaseURL, path, nil, refID)
+	if err != nil {
+		return nil, err
+	}
+
+	var card wallet.WalletCard
+	if err := json.Unmarshal(body, &card); err != nil {
+		return nil, fmt.Errorf("failed to parse card data from partner: %v", err)
+	}
+	return &card, nil
+}
+
+// SendCardState calls the partner's Send Card State endpoint the way Samsung Wallet
+// does when a user adds, deletes or otherwise changes a card
+//
+// The request is POST {partnerBaseURL}/cards/{cardId}/{refId}?cc2={cc2}&event={state}
+// with a CardStateCallback body, so it can be handled by Client.HandleCallback.
+func (s *Server) SendCardState(ctx context.Context, partnerBaseURL, cardID, refID, countryCode string, state wallet.CardState) error {
+	query := url.Values{}
+	query.Set("cc2", countryCode)
+	query.Set("event", string(state))
+	path := fmt.Sprintf("/cards/%s/%s", url.PathEscape(cardID), url.PathEscape(refID))
+
+	payload, err := json.Marshal(wallet.CardStateCallback{
+		PartnerID:   s.PartnerID,
+		CardID:      refID,
+		Event:       state,
+		CountryCode: countryCode,
+		Timestamp:   time.Now().UTC(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal card state: %v", err)
+	}
+
+	_, err = s.callPartner(ctx, http.MethodPost, partnerBaseURL, path+"?"+query.Encode(), payload, refID)
+	return err
+}
+
+// callPartner sends a Samsung-authenticated request to the partner server
+func (s *Server) callPartner(ctx context.Context, method, partnerBaseURL, pathAndQuery string, payload []byte, refID string) ([]byte, error) {
+	path, _, _ := strings.Cut(pathAndQuery, "?")
+	token, err := s.samsungAuthToken(method, path, refID)
+	if err != nil {
+		return nil, err
+	}
+
+	var body io.Reader
+	if payload != nil {
+		body = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimRight(partnerBaseURL, "/")+pathAndQuery, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create partner request: %v", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("x-smcs-partner-id", s.PartnerID)
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call partner: %v", err)
+	}
+	defer resp.Body.Close()
+
+	responseBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read partner response: %v", err)
+	}
+	if resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("partner responded with status %d: %s", resp.StatusCode, responseBody)
+	}
+
+	return responseBody, nil
+}
+
+// samsungAuthToken creates the token Samsung Wallet sends to partner servers,
+// signed with the fake server's Samsung key
+func (s *Server) samsungAuthToken(method, path, refID string) (string, error) {
+	payload, err := json.Marshal(wallet.AuthTokenPayload{
+		API:   wallet.AuthTokenAPI{Method: method, Path: path},
+		RefID: refID,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal samsung token payload: %v", err)
+	}
+
+	signer, err := jose.NewSigner(
+		jose.SigningKey{Algorithm: jose.RS256, Key: s.keys.SamsungKey},
+		(&jose.SignerOptions{}).WithType("JWT").WithHeader("cty", "AUTH").
+			WithHeader("partnerId", s.PartnerID).
+			WithHeader("ver", "3").
+			WithHeader("utc", time.Now().UnixMilli()),
+	)
+	if err != nil {
+		return "", fmt.Errorf("failed to create samsung signer: %v", err)
+	}
+
+	jws, err := signer.Sign(payload)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign samsung token: %v", err)
+	}
+	return jws.CompactSerialize()
+}
@@ -0,0 +1,553 @@
+// Package wallettest provides a fake Samsung Wallet server for integration tests
+package wallettest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v3"
+
+	"github.com/abyssparanoia/samsung-wallet-go/wallet"
+	"github.com/abyssparanoia/samsung-wallet-go/wallet/testkeys"
+)
+
+const (
+	// ResultCodeSuccess is the Samsung result code returned for accepted calls
+	ResultCodeSuccess = "0"
+)
+
+// CallKind identifies which Samsung server API a recorded call targeted
+type CallKind string
+
+const (
+	CallUpdate  CallKind = "update"   // Update notification
+	CallCancel  CallKind = "cancel"   // Cancel notification
+	CallGetCard CallKind = "get_card" // Get card data
+	CallAddCard CallKind = "add_card" // Server-to-server add card
+)
+
+// Call records a request received by the fake server
+type Call struct {
+	Kind        CallKind
+	Method      string
+	Path        string
+	CountryCode string
+	CardID      string
+	RequestID   string
+	Header      http.Header
+	Body        []byte
+	Auth        *wallet.AuthTokenPayload // Verified authorization token payload
+	Card        *wallet.WalletCard       // Decrypted CDATA, for add card calls
+	Err         error                    // Validation failure reported back to the client, if any
+}
+
+// Server is a fake Samsung Wallet server backed by httptest
+//
+// It issues its own partner and Samsung key pairs, verifies the partner
+// authorization token and CDATA of every call, and records the calls so tests
+// can assert on them.
+type Server struct {
+	URL string // Base URL of the server, e.g. http://127.0.0.1:1234
+
+	PartnerID     string
+	CertificateID string
+
+	server *httptest.Server
+	keys   *testkeys.Fixture
+
+	mu       sync.Mutex
+	calls    []Call
+	cards    map[string]wallet.WalletCard // Cards known to the server by refId
+	cardData map[string]wallet.CardData   // Legacy card data served by get card, by card ID
+	failures map[CallKind][]injectedFailure
+}
+
+// injectedFailure is a canned error response returned instead of handling a call
+type injectedFailure struct {
+	statusCode int
+	resultCode string
+	message    string
+	header     http.Header
+}
+
+// resultBody is the Samsung result envelope
+type resultBody struct {
+	ResultCode    string `json:"resultCode"`
+	ResultMessage string `json:"resultMessage"`
+}
+
+// NewServer starts a fake Samsung Wallet server that is closed when the test ends
+func NewServer(tb testing.TB) *Server {
+	tb.Helper()
+
+	keys, err := testkeys.Generate()
+	if err != nil {
+		tb.Fatalf("wallettest: %v", err)
+	}
+
+	return NewServerWithKeys(tb, keys)
+}
+
+// NewServerWithKeys starts a fake Samsung Wallet server that trusts the given fixture
+func NewServerWithKeys(tb testing.TB, keys *testkeys.Fixture) *Server {
+	tb.Helper()
+
+	s := &Server{
+		PartnerID:     keys.PartnerID,
+		CertificateID: keys.CertificateID,
+		keys:          keys,
+		cards:         make(map[string]wallet.WalletCard),
+		cardData:      make(map[string]wallet.CardData),
+		failures:      make(map[CallKind][]injectedFailure),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /{cc2}/wltex/cards/{cardId}/updates", s.handle(CallUpdate, s.handleUpdate))
+	mux.HandleFunc("POST /{cc2}/wltex/cards/{cardId}/cancels", s.handle(CallCancel, s.handleCancel))
+	mux.HandleFunc("POST /{cc2}/atw/v1/cards/{cardId}", s.handle(CallAddCard, s.handleAddCard))
+	mux.HandleFunc("POST /v1/wallet/card/get/{cc2}", s.handle(CallGetCard, s.handleGetCard))
+
+	s.server = httptest.NewServer(mux)
+	s.URL = s.server.URL
+	tb.Cleanup(s.Close)
+
+	return s
+}
+
+// Close shuts the server down
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+// Config returns a client configuration that talks to the fake server
+func (s *Server) Config() *wallet.Config {
+	config := s.keys.Config()
+	config.PartnerID = s.PartnerID
+	config.CertificateID = s.CertificateID
+	config.APIBaseURL = s.URL
+	return config
+}
+
+// Keys returns the key fixture shared by the server and its clients
+func (s *Server) Keys() *testkeys.Fixture {
+	return s.keys
+}
+
+// Client returns a client configured for the fake server
+// Retries use millisecond backoff so injected failures don't slow tests down
+func (s *Server) Client(tb testing.TB) *wallet.Client {
+	tb.Helper()
+
+	client, err := wallet.NewClient(s.Config())
+	if err != nil {
+		tb.Fatalf("wallettest: failed to create client: %v", err)
+	}
+
+	policy := wallet.DefaultRetryPolicy()
+	policy.InitialBackoff = time.Millisecond
+	policy.MaxBackoff = 10 * time.Millisecond
+	client.SetRetryPolicy(policy)
+
+	return client
+}
+
+// Calls returns every call received so far
+func (s *Server) Calls() []Call {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Call(nil), s.calls...)
+}
+
+// CallsOf returns the calls of one kind received so far
+func (s *Server) CallsOf(kind CallKind) []Call {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var calls []Call
+	for _, call := range s.calls {
+		if call.Kind == kind {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+// Reset forgets recorded calls, stored cards and pending failures
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.calls = nil
+	s.cards = make(map[string]wallet.WalletCard)
+	s.cardData = make(map[string]wallet.CardData)
+	s.failures = make(map[CallKind][]injectedFailure)
+}
+
+// Card returns the card stored under refID, either added or updated inline
+func (s *Server) Card(refID string) (wallet.WalletCard, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	card, ok := s.cards[refID]
+	return card, ok
+}
+
+// PutCard stores a card as if a user had already added it
+func (s *Server) PutCard(card wallet.WalletCard) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, data := range card.Card.Data {
+		s.cards[data.RefID] = singleCard(card, data)
+	}
+}
+
+// SetCardData sets the legacy card data returned by get card for cardID
+func (s *Server) SetCardData(cardID string, data wallet.CardData) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cardData[cardID] = data
+}
+
+// FailNext makes the next call of the given kind fail with an error response
+// Failures queue up, so calling it twice fails the next two calls
+func (s *Server) FailNext(kind CallKind, statusCode int, resultCode, message string) {
+	s.failNext(kind, injectedFailure{statusCode: statusCode, resultCode: resultCode, message: message})
+}
+
+// RateLimitNext makes the next call of the given kind fail with 429 and a Retry-After header
+func (s *Server) RateLimitNext(kind CallKind, retryAfter time.Duration) {
+	header := http.Header{}
+	header.Set("Retry-After", fmt.Sprintf("%d", int(retryAfter.Seconds())))
+	s.failNext(kind, injectedFailure{
+		statusCode: http.StatusTooManyRequests,
+		resultCode: "429",
+		message:    "too many requests",
+		header:     header,
+	})
+}
+
+func (s *Server) failNext(kind CallKind, failure injectedFailure) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures[kind] = append(s.failures[kind], failure)
+}
+
+// popFailure returns the next injected failure for kind, if any
+func (s *Server) popFailure(kind CallKind) (injectedFailure, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	queue := s.failures[kind]
+	if len(queue) == 0 {
+		return injectedFailure{}, false
+	}
+	s.failures[kind] = queue[1:]
+	return queue[0], true
+}
+
+// record appends a call to the log
+func (s *Server) record(call Call) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls = append(s.calls, call)
+}
+
+// callHandler handles a validated call and returns the response body
+type callHandler func(call *Call) (interface{}, error)
+
+// handle wraps a call handler with recording, failure injection and authentication
+func (s *Server) handle(kind CallKind, handler callHandler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		call := Call{
+			Kind:        kind,
+			Method:      r.Method,
+			Path:        r.URL.EscapedPath(),
+			CountryCode: r.PathValue("cc2"),
+			CardID:      r.PathValue("cardId"),
+			RequestID:   r.Header.Get("x-request-id"),
+			Header:      r.Header.Clone(),
+			Body:        body,
+		}
+
+		if failure, ok := s.popFailure(kind); ok {
+			call.Err = fmt.Errorf("injected failure: %d %s", failure.statusCode, failure.message)
+			s.record(call)
+			for key, values := range failure.header {
+				w.Header()[key] = values
+			}
+			writeResult(w, failure.statusCode, failure.resultCode, failure.message)
+			return
+		}
+
+		if err := s.authenticate(r, &call); err != nil {
+			call.Err = err
+			s.record(call)
+			writeResult(w, http.StatusUnauthorized, "401", err.Error())
+			return
+		}
+
+		response, err := handler(&call)
+		if err != nil {
+			call.Err = err
+			s.record(call)
+			statusCode := http.StatusBadRequest
+			if errors.Is(err, errNotFound) {
+				statusCode = http.StatusNotFound
+			}
+			writeResult(w, statusCode, fmt.Sprintf("%d", statusCode), err.Error())
+			return
+		}
+
+		s.record(call)
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(response)
+	}
+}
+
+// errNotFound marks handler errors answered with 404
+var errNotFound = errors.New("not found")
+
+// authenticate checks the partner headers and authorization token of a call
+func (s *Server) authenticate(r *http.Request, call *Call) error {
+	if partnerID := r.Header.Get("x-smcs-partner-id"); partnerID != s.PartnerID {
+		return fmt.Errorf("unexpected partner ID %q", partnerID)
+	}
+	if call.RequestID == "" {
+		return fmt.Errorf("missing x-request-id header")
+	}
+
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || token == "" {
+		return fmt.Errorf("missing bearer token")
+	}
+
+	payload, headers, err := s.verifyPartnerJWS(token)
+	if err != nil {
+		return fmt.Errorf("invalid authorization token: %v", err)
+	}
+	if headers["cty"] != "AUTH" {
+		return fmt.Errorf("authorization token has cty %v, want AUTH", headers["cty"])
+	}
+
+	var auth wallet.AuthTokenPayload
+	if err := json.Unmarshal(payload, &auth); err != nil {
+		return fmt.Errorf("invalid authorization token payload: %v", err)
+	}
+	if auth.API.Method != r.Method || auth.API.Path != call.Path {
+		return fmt.Errorf("authorization token is bound to %s %s", auth.API.Method, auth.API.Path)
+	}
+
+	call.Auth = &auth
+	return nil
+}
+
+// verifyPartnerJWS verifies a JWS signed with the partner key and checks the Samsung headers
+func (s *Server) verifyPartnerJWS(token string) ([]byte, map[jose.HeaderKey]interface{}, error) {
+	jws, err := jose.ParseSigned(token)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(jws.Signatures) != 1 {
+		return nil, nil, fmt.Errorf("expected exactly one signature")
+	}
+
+	payload, err := jws.Verify(&s.keys.PartnerKey.PublicKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	headers := jws.Signatures[0].Header.ExtraHeaders
+	if headers["partnerId"] != s.PartnerID {
+		return nil, nil, fmt.Errorf("unexpected partnerId header %v", headers["partnerId"])
+	}
+	if headers["certificateId"] != s.CertificateID {
+		return nil, nil, fmt.Errorf("unexpected certificateId header %v", headers["certificateId"])
+	}
+	if headers["ver"] != "3" {
+		return nil, nil, fmt.Errorf("unexpected ver header %v", headers["ver"])
+	}
+	if _, ok := headers["utc"].(float64); !ok {
+		return nil, nil, fmt.Errorf("missing utc header")
+	}
+
+	return payload, headers, nil
+}
+
+// DecryptCDATA verifies and decrypts CDATA generated for the fake server
+func (s *Server) DecryptCDATA(cdata string) (*wallet.WalletCard, error) {
+	_, headers, err := s.verifyPartnerJWS(cdata)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CDATA signature: %v", err)
+	}
+	if headers["cty"] != "CARD" {
+		return nil, fmt.Errorf("CDATA has cty %v, want CARD", headers["cty"])
+	}
+
+	plaintext, err := s.keys.DecryptCDATA(cdata)
+	if err != nil {
+		return nil, err
+	}
+
+	var card wallet.WalletCard
+	if err := json.Unmarshal(plaintext, &card); err != nil {
+		return nil, fmt.Errorf("invalid card data in CDATA: %v", err)
+	}
+	return &card, nil
+}
+
+// notificationRequest is the body of update and cancel notifications
+type notificationRequest struct {
+	Card struct {
+		Type    string `json:"type"`
+		SubType string `json:"subType"`
+		Data    []struct {
+			wallet.WalletCardData
+			EventID string           `json:"eventId"`
+			State   wallet.CardState `json:"state"`
+		} `json:"data"`
+	} `json:"card"`
+}
+
+func (s *Server) handleUpdate(call *Call) (interface{}, error) {
+	var request notificationRequest
+	if err := json.Unmarshal(call.Body, &request); err != nil {
+		return nil, fmt.Errorf("invalid update notification: %v", err)
+	}
+	if request.Card.Type == "" || len(request.Card.Data) == 0 || request.Card.Data[0].RefID == "" {
+		return nil, fmt.Errorf("update notification requires card type and refId")
+	}
+
+	// Inline card data replaces the stored card
+	data := request.Card.Data[0]
+	if data.Attributes != nil {
+		s.mu.Lock()
+		s.cards[data.RefID] = wallet.WalletCard{Card: wallet.WalletCardBody{
+			Type:    request.Card.Type,
+			SubType: request.Card.SubType,
+			Data:    []wallet.WalletCardData{data.WalletCardData},
+		}}
+		s.mu.Unlock()
+	}
+
+	return resultBody{ResultCode: ResultCodeSuccess, ResultMessage: "SUCCESS"}, nil
+}
+
+func (s *Server) handleCancel(call *Call) (interface{}, error) {
+	var request notificationRequest
+	if err := json.Unmarshal(call.Body, &request); err != nil {
+		return nil, fmt.Errorf("invalid cancel notification: %v", err)
+	}
+	if request.Card.Type == "" || len(request.Card.Data) == 0 {
+		return nil, fmt.Errorf("cancel notification requires card type and data")
+	}
+
+	type canceledCard struct {
+		RefID   string           `json:"refId"`
+		EventID string           `json:"eventId,omitempty"`
+		State   wallet.CardState `json:"state"`
+	}
+
+	var canceled []canceledCard
+	for _, data := range request.Card.Data {
+		if data.RefID != "" {
+			canceled = append(canceled, canceledCard{RefID: data.RefID, EventID: data.EventID, State: wallet.CardStateCanceled})
+			continue
+		}
+
+		// Without refIds every stored card of the event is cancelled
+		s.mu.Lock()
+		for refID, card := range s.cards {
+			if len(card.Card.Data) > 0 && card.Card.Data[0].Attributes["eventId"] == data.EventID {
+				canceled = append(canceled, canceledCard{RefID: refID, EventID: data.EventID, State: wallet.CardStateCanceled})
+			}
+		}
+		s.mu.Unlock()
+	}
+
+	return struct {
+		resultBody
+		Data []canceledCard `json:"data"`
+	}{
+		resultBody: resultBody{ResultCode: ResultCodeSuccess, ResultMessage: "SUCCESS"},
+		Data:       canceled,
+	}, nil
+}
+
+func (s *Server) handleAddCard(call *Call) (interface{}, error) {
+	var request struct {
+		Card struct {
+			CDATA string `json:"cdata"`
+		} `json:"card"`
+		Target struct {
+			UserID   string `json:"userId"`
+			DeviceID string `json:"deviceId"`
+		} `json:"target"`
+	}
+	if err := json.Unmarshal(call.Body, &request); err != nil {
+		return nil, fmt.Errorf("invalid add card request: %v", err)
+	}
+	if request.Target.UserID == "" && request.Target.DeviceID == "" {
+		return nil, fmt.Errorf("add card requires a target user or device")
+	}
+
+	card, err := s.DecryptCDATA(request.Card.CDATA)
+	if err != nil {
+		return nil, err
+	}
+	if len(card.Card.Data) == 0 {
+		return nil, fmt.Errorf("card has no data")
+	}
+	call.Card = card
+
+	s.PutCard(*card)
+
+	return struct {
+		resultBody
+		RefID string           `json:"refId"`
+		State wallet.CardState `json:"state"`
+	}{
+		resultBody: resultBody{ResultCode: ResultCodeSuccess, ResultMessage: "SUCCESS"},
+		RefID:      card.Card.Data[0].RefID,
+		State:      wallet.CardStateAdded,
+	}, nil
+}
+
+func (s *Server) handleGetCard(call *Call) (interface{}, error) {
+	var request struct {
+		CardID string `json:"card_id"`
+	}
+	if err := json.Unmarshal(call.Body, &request); err != nil {
+		return nil, fmt.Errorf("invalid get card request: %v", err)
+	}
+	call.CardID = request.CardID
+
+	s.mu.Lock()
+	data, ok := s.cardData[request.CardID]
+	s.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("card %s: %w", request.CardID, errNotFound)
+	}
+	return data, nil
+}
+
+// singleCard returns a copy of card that only holds data
+func singleCard(card wallet.WalletCard, data wallet.WalletCardData) wallet.WalletCard {
+	card.Card.Data = []wallet.WalletCardData{data}
+	return card
+}
+
+// writeResult writes a Samsung result envelope
+func writeResult(w http.ResponseWriter, statusCode int, resultCode, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(resultBody{ResultCode: resultCode, ResultMessage: message})
+}
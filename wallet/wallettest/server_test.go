@@ -0,0 +1,241 @@
+package wallettest_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/abyssparanoia/samsung-wallet-go/wallet"
+	"github.com/abyssparanoia/samsung-wallet-go/wallet/testkeys"
+	"github.com/abyssparanoia/samsung-wallet-go/wallet/wallettest"
+)
+
+// testCard returns a ticket the fake server can store
+func testCard(refID, eventID string) wallet.WalletCard {
+	return wallet.WalletCard{Card: wallet.WalletCardBody{
+		Type:    "ticket",
+		SubType: "others",
+		Data: []wallet.WalletCardData{{
+			RefID:      refID,
+			Language:   "en",
+			Attributes: wallet.WalletCardAttributes{"title": "Concert", "eventId": eventID},
+		}},
+	}}
+}
+
+// TestCancelEvent runs the README example
+func TestCancelEvent(t *testing.T) {
+	ctx := context.Background()
+	server := wallettest.NewServer(t)
+	client := server.Client(t)
+
+	server.PutCard(testCard("ref-1", "EVENT001"))
+	server.PutCard(testCard("ref-2", "EVENT002"))
+	server.FailNext(wallettest.CallCancel, http.StatusServiceUnavailable, "503", "maintenance")
+
+	result, err := client.SendCancelNotification(ctx, &wallet.CancelNotificationRequest{
+		CardID: "card", CountryCode: "KR", EventID: "EVENT001",
+	})
+	if err != nil {
+		t.Fatalf("SendCancelNotification() error = %v", err)
+	}
+	if len(result.Cards) != 1 || result.Cards[0].RefID != "ref-1" || result.Cards[0].State != wallet.CardStateCanceled {
+		t.Errorf("SendCancelNotification() cards = %+v, want ref-1 cancelled", result.Cards)
+	}
+
+	calls := server.CallsOf(wallettest.CallCancel)
+	if len(calls) != 2 {
+		t.Fatalf("cancel calls = %d, want 2 (retried once after the injected 503)", len(calls))
+	}
+	if calls[0].Err == nil || calls[1].Err != nil {
+		t.Errorf("call errors = %v, %v, want the injected failure then success", calls[0].Err, calls[1].Err)
+	}
+	if calls[1].CountryCode != "KR" || calls[1].CardID != "card" || calls[1].Auth == nil {
+		t.Errorf("call = %+v, want an authenticated KR call for card", calls[1])
+	}
+}
+
+func TestFailNextQueues(t *testing.T) {
+	server := wallettest.NewServer(t)
+	client := server.Client(t)
+
+	for i := 0; i < 3; i++ {
+		server.FailNext(wallettest.CallCancel, http.StatusServiceUnavailable, "503", "maintenance")
+	}
+	_, err := client.SendCancelNotification(context.Background(), &wallet.CancelNotificationRequest{
+		CardID: "card", CountryCode: "KR", EventID: "EVENT001",
+	})
+	if !errors.Is(err, wallet.ErrServerError) {
+		t.Errorf("SendCancelNotification() error = %v, want ErrServerError", err)
+	}
+	if calls := server.CallsOf(wallettest.CallCancel); len(calls) != 3 {
+		t.Errorf("cancel calls = %d, want 3", len(calls))
+	}
+
+	// Failures of one kind leave other calls alone
+	server.FailNext(wallettest.CallCancel, http.StatusServiceUnavailable, "503", "maintenance")
+	if _, err := client.SendUpdateNotification(context.Background(), &wallet.UpdateNotificationRequest{
+		CardID: "card", CountryCode: "KR", RefID: "ref-1", CardType: "ticket",
+	}); err != nil {
+		t.Errorf("SendUpdateNotification() error = %v", err)
+	}
+}
+
+func TestRateLimitNext(t *testing.T) {
+	server := wallettest.NewServer(t)
+	client := server.Client(t)
+
+	// Retry-After exceeds the client's 10ms MaxBackoff, so the client gives up
+	server.RateLimitNext(wallettest.CallUpdate, 2*time.Second)
+	_, err := client.SendUpdateNotification(context.Background(), &wallet.UpdateNotificationRequest{
+		CardID: "card", CountryCode: "KR", RefID: "ref-1", CardType: "ticket",
+	})
+	if !errors.Is(err, wallet.ErrRateLimited) {
+		t.Errorf("SendUpdateNotification() error = %v, want ErrRateLimited", err)
+	}
+	calls := server.CallsOf(wallettest.CallUpdate)
+	if len(calls) != 1 {
+		t.Fatalf("update calls = %d, want 1", len(calls))
+	}
+}
+
+func TestUpdateStoresInlineCard(t *testing.T) {
+	server := wallettest.NewServer(t)
+	client := server.Client(t)
+
+	card := testCard("ref-1", "EVENT001")
+	card.Card.Data[0].Attributes["title"] = "Concert (moved)"
+	if _, err := client.SendUpdateNotification(context.Background(), &wallet.UpdateNotificationRequest{
+		CardID: "card", CountryCode: "KR", RefID: "ref-1", Card: &card,
+	}); err != nil {
+		t.Fatalf("SendUpdateNotification() error = %v", err)
+	}
+
+	stored, ok := server.Card("ref-1")
+	if !ok || stored.Card.Data[0].Attributes["title"] != "Concert (moved)" {
+		t.Errorf("Card(ref-1) = %+v, %v, want the updated card", stored, ok)
+	}
+
+	server.Reset()
+	if _, ok := server.Card("ref-1"); ok || len(server.Calls()) != 0 {
+		t.Error("Reset() kept cards or calls")
+	}
+}
+
+func TestAddCard(t *testing.T) {
+	server := wallettest.NewServer(t)
+	client := server.Client(t)
+
+	result, err := client.AddCard(context.Background(), &wallet.AddCardRequest{
+		CardID: "card", CountryCode: "KR", UserID: "user-1", Card: testCard("ref-1", "EVENT001"),
+	})
+	if err != nil {
+		t.Fatalf("AddCard() error = %v", err)
+	}
+	if result.RefID != "ref-1" || result.State != wallet.CardStateAdded {
+		t.Errorf("AddCard() = %+v, want ref-1 added", result)
+	}
+
+	calls := server.CallsOf(wallettest.CallAddCard)
+	if len(calls) != 1 || calls[0].Card == nil || calls[0].Card.Card.Data[0].Attributes["title"] != "Concert" {
+		t.Fatalf("add card calls = %+v, want one call with the decrypted card", calls)
+	}
+	if _, ok := server.Card("ref-1"); !ok {
+		t.Error("AddCard() did not store the card")
+	}
+}
+
+func TestGetCard(t *testing.T) {
+	server := wallettest.NewServer(t)
+	client := server.Client(t)
+
+	server.SetCardData("card-1", wallet.CardData{CardID: "card-1", Name: "Concert"})
+	data, err := client.GetCardDataWithContext(context.Background(), "card-1", "KR")
+	if err != nil {
+		t.Fatalf("GetCardDataWithContext() error = %v", err)
+	}
+	if data.CardID != "card-1" || data.Name != "Concert" {
+		t.Errorf("GetCardDataWithContext() = %+v, want card-1", data)
+	}
+
+	// Unknown cards are answered with 404 and not retried
+	if _, err := client.GetCardData("missing", "KR"); !errors.Is(err, wallet.ErrNotFound) {
+		t.Errorf("GetCardData(missing) error = %v, want ErrNotFound", err)
+	}
+	calls := server.CallsOf(wallettest.CallGetCard)
+	if len(calls) != 2 || calls[0].CardID != "card-1" || calls[1].Err == nil {
+		t.Errorf("get card calls = %+v, want card-1 then a failed call", calls)
+	}
+}
+
+func TestRejectsForeignKeys(t *testing.T) {
+	server := wallettest.NewServer(t)
+
+	// A client signing with other partner keys fails authentication
+	config := testkeys.MustGenerate().Config()
+	config.APIBaseURL = server.URL
+	client, err := wallet.NewClient(config)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	_, err = client.SendUpdateNotification(context.Background(), &wallet.UpdateNotificationRequest{
+		CardID: "card", CountryCode: "KR", RefID: "ref-1", CardType: "ticket",
+	})
+	if !errors.Is(err, wallet.ErrAuthFailure) {
+		t.Errorf("SendUpdateNotification() error = %v, want ErrAuthFailure", err)
+	}
+	if calls := server.Calls(); len(calls) != 1 || calls[0].Err == nil {
+		t.Errorf("calls = %+v, want one rejected call", calls)
+	}
+}
+
+func TestPartnerCalls(t *testing.T) {
+	server := wallettest.NewServer(t)
+	client := server.Client(t)
+
+	var (
+		requests []*http.Request
+		bodies   [][]byte
+	)
+	partner := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests, bodies = append(requests, r), append(bodies, body)
+		if r.Method == http.MethodGet {
+			_ = json.NewEncoder(w).Encode(testCard("ref-1", "EVENT001"))
+		}
+	}))
+	defer partner.Close()
+
+	card, err := server.FetchCardData(context.Background(), partner.URL, "card", "ref-1")
+	if err != nil {
+		t.Fatalf("FetchCardData() error = %v", err)
+	}
+	if card.Card.Data[0].RefID != "ref-1" {
+		t.Errorf("FetchCardData() = %+v, want ref-1", card)
+	}
+
+	if err := server.SendCardState(context.Background(), partner.URL, "card", "ref-1", "KR", wallet.CardStateAdded); err != nil {
+		t.Fatalf("SendCardState() error = %v", err)
+	}
+
+	if len(requests) != 2 {
+		t.Fatalf("partner requests = %d, want 2", len(requests))
+	}
+	if r := requests[0]; r.Method != http.MethodGet || r.URL.Path != "/cards/card/ref-1" || r.Header.Get("Authorization") == "" {
+		t.Errorf("get card data request = %s %s, want an authenticated GET /cards/card/ref-1", r.Method, r.URL)
+	}
+	if r := requests[1]; r.Method != http.MethodPost || r.URL.Query().Get("cc2") != "KR" || r.URL.Query().Get("event") != string(wallet.CardStateAdded) {
+		t.Errorf("send card state request = %s %s, want POST with cc2 and event", r.Method, r.URL)
+	}
+
+	callback, err := client.HandleCallback(bodies[1])
+	if err != nil || callback.Event != wallet.CardStateAdded || callback.CountryCode != "KR" {
+		t.Errorf("HandleCallback() = %+v, %v, want an added callback", callback, err)
+	}
+}